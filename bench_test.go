@@ -102,6 +102,83 @@ func BenchmarkLogger_JsonCtx(b *testing.B) {
 	})
 }
 
+func BenchmarkLogger_MsgPackCtx(b *testing.B) {
+	log := logger.New(discard{}, logger.MsgPackFormat(), logger.Debug).With(ctx.Str("_n", "bench"), ctx.Int("_p", 1))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Error("some message", ctx.Int("key", 1), ctx.Float64("key2", 3.141592), ctx.Str("key3", "string"), ctx.Bool("key4", false))
+		}
+	})
+}
+
+func BenchmarkLogger_ECSFlatCtx(b *testing.B) {
+	log := logger.New(discard{}, logger.ECSFormat(), logger.Debug).With(ctx.Str("_n", "bench"), ctx.Int("_p", 1))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Error("some message", ctx.Int("key", 1), ctx.Float64("key2", 3.141592), ctx.Str("key3", "string"), ctx.Bool("key4", false))
+		}
+	})
+}
+
+func BenchmarkLogger_ECSCtx(b *testing.B) {
+	log := logger.New(discard{}, logger.ECSFormat(), logger.Debug).With(ctx.Str("service.name", "bench"), ctx.Int("process.pid", 1))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Error("some message", ctx.Int("key", 1), ctx.Float64("key2", 3.141592), ctx.Str("key3", "string"), ctx.Bool("key4", false))
+		}
+	})
+}
+
+func BenchmarkLogger_LogfmtCtxRedactor(b *testing.B) {
+	r := logger.NewRedactor(logger.RedactKeys("password", "*_token"), logger.RedactValues(logger.EmailPattern))
+	log := logger.New(discard{}, logger.LogfmtFormat(), logger.Debug).WithRedactor(r).With(ctx.Str("_n", "bench"), ctx.Int("_p", 1))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Error("some message", ctx.Int("key", 1), ctx.Float64("key2", 3.141592), ctx.Str("key3", "string"), ctx.Bool("key4", false))
+		}
+	})
+}
+
+func BenchmarkLogger_LogfmtCtxDuplicatePolicy(b *testing.B) {
+	policies := []struct {
+		name   string
+		policy logger.DuplicatePolicy
+	}{
+		{name: "KeepAll", policy: logger.DuplicateKeepAll},
+		{name: "LastWins", policy: logger.DuplicateLastWins},
+		{name: "FirstWins", policy: logger.DuplicateFirstWins},
+		{name: "Rename", policy: logger.DuplicateRename},
+	}
+
+	for _, p := range policies {
+		b.Run(p.name, func(b *testing.B) {
+			log := logger.New(discard{}, logger.LogfmtFormat(), logger.Debug).
+				WithDuplicatePolicy(p.policy).
+				With(ctx.Str("_n", "bench"), ctx.Int("key", 1))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					log.Error("some message", ctx.Int("key", 1), ctx.Float64("key2", 3.141592), ctx.Str("key3", "string"), ctx.Bool("key4", false))
+				}
+			})
+		})
+	}
+}
+
 func BenchmarkLogger_WithContext(b *testing.B) {
 	log := logger.New(discard{}, logger.LogfmtFormat(), logger.Debug)
 	goCtx := context.Background()
@@ -0,0 +1,187 @@
+package logger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hamba/logger/v2/internal/bytes"
+)
+
+// BytesAppender is implemented by formatters that write byte slices as
+// binary values, rather than as arrays of ints.
+type BytesAppender interface {
+	AppendBytes(buf *bytes.Buffer, p []byte)
+}
+
+// CBOR major types.
+const (
+	cborUint byte = iota << 5
+	cborNegInt
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+const (
+	cborFalse      = cborSimple | 20
+	cborTrue       = cborSimple | 21
+	cborNull       = cborSimple | 22
+	cborFloat32    = cborSimple | 26
+	cborFloat64    = cborSimple | 27
+	cborIndefinite = 31
+	cborBreak      = 0xff
+
+	cborTagDateTime = 0
+	cborTagEpoch    = 1
+)
+
+type cbor struct{}
+
+// CBORFormat formats a log line as a CBOR map.
+//
+// Each entry is written as a self-describing map, forming a CBOR sequence
+// without line breaks. Values are written with their native CBOR types:
+// times as epoch times (tag 1), or RFC 3339 strings (tag 0) when not a
+// whole second to keep nanoseconds, durations as integer nanoseconds, byte
+// slices as byte strings and arrays as CBOR arrays.
+func CBORFormat() Formatter {
+	return &cbor{}
+}
+
+func (c *cbor) WriteMessage(buf *bytes.Buffer, ts time.Time, lvl Level, msg string) {
+	if !ts.IsZero() {
+		c.AppendKey(buf, TimestampKey)
+		c.AppendTime(buf, ts)
+	}
+	c.AppendKey(buf, LevelKey)
+	c.AppendString(buf, lvl.String())
+	c.AppendKey(buf, MessageKey)
+	c.AppendString(buf, msg)
+}
+
+func (c *cbor) AppendBeginMarker(buf *bytes.Buffer) {
+	buf.WriteByte(cborMap | cborIndefinite)
+}
+
+func (c *cbor) AppendEndMarker(buf *bytes.Buffer) {
+	buf.WriteByte(cborBreak)
+}
+
+func (c *cbor) AppendLineBreak(*bytes.Buffer) {}
+
+func (c *cbor) AppendArrayStart(buf *bytes.Buffer) {
+	buf.WriteByte(cborArray | cborIndefinite)
+}
+
+func (c *cbor) AppendArraySep(*bytes.Buffer) {}
+
+func (c *cbor) AppendArrayEnd(buf *bytes.Buffer) {
+	buf.WriteByte(cborBreak)
+}
+
+func (c *cbor) AppendKey(buf *bytes.Buffer, key string) {
+	c.AppendString(buf, key)
+}
+
+func (c *cbor) AppendString(buf *bytes.Buffer, s string) {
+	// Text strings must be valid UTF-8.
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, string(utf8.RuneError))
+	}
+	appendCBORHead(buf, cborText, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+func (c *cbor) AppendBytes(buf *bytes.Buffer, p []byte) {
+	appendCBORHead(buf, cborBytes, uint64(len(p)))
+	buf.Write(p)
+}
+
+func (c *cbor) AppendBool(buf *bytes.Buffer, b bool) {
+	if b {
+		buf.WriteByte(cborTrue)
+		return
+	}
+	buf.WriteByte(cborFalse)
+}
+
+func (c *cbor) AppendInt(buf *bytes.Buffer, i int64) {
+	if i < 0 {
+		appendCBORHead(buf, cborNegInt, uint64(^i))
+		return
+	}
+	appendCBORHead(buf, cborUint, uint64(i))
+}
+
+func (c *cbor) AppendUint(buf *bytes.Buffer, i uint64) {
+	appendCBORHead(buf, cborUint, i)
+}
+
+func (c *cbor) AppendFloat(buf *bytes.Buffer, f float64) {
+	var b [8]byte
+	// Floats are written as single precision when that is lossless.
+	if f32 := float32(f); float64(f32) == f || math.IsNaN(f) {
+		buf.WriteByte(cborFloat32)
+		binary.BigEndian.PutUint32(b[:4], math.Float32bits(f32))
+		buf.Write(b[:4])
+		return
+	}
+	buf.WriteByte(cborFloat64)
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(f))
+	buf.Write(b[:])
+}
+
+// AppendTime appends an epoch time, or an RFC 3339 time if the time is
+// not a whole second, as float epoch times lose nanoseconds.
+func (c *cbor) AppendTime(buf *bytes.Buffer, t time.Time) {
+	if t.Nanosecond() == 0 {
+		appendCBORHead(buf, cborTag, cborTagEpoch)
+		c.AppendInt(buf, t.Unix())
+		return
+	}
+	appendCBORHead(buf, cborTag, cborTagDateTime)
+	c.AppendString(buf, t.Format(time.RFC3339Nano))
+}
+
+func (c *cbor) AppendDuration(buf *bytes.Buffer, d time.Duration) {
+	c.AppendInt(buf, int64(d))
+}
+
+func (c *cbor) AppendInterface(buf *bytes.Buffer, v any) {
+	if v == nil {
+		buf.WriteByte(cborNull)
+		return
+	}
+
+	c.AppendString(buf, fmt.Sprintf("%+v", v))
+}
+
+func appendCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	var b [8]byte
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		binary.BigEndian.PutUint16(b[:2], uint16(n))
+		buf.Write(b[:2])
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		binary.BigEndian.PutUint32(b[:4], uint32(n))
+		buf.Write(b[:4])
+	default:
+		buf.WriteByte(major | 27)
+		binary.BigEndian.PutUint64(b[:], n)
+		buf.Write(b[:])
+	}
+}
@@ -0,0 +1,331 @@
+// Package cbor implements decoding of entries written by logger.CBORFormat.
+//
+// Entries can be decoded into fields, or converted to json lines:
+//
+//	if err := cbor.ToJSON(os.Stdout, os.Stdin); err != nil {
+//		// Handle error.
+//	}
+package cbor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// maxLength is the maximum length of a decoded string or container.
+	maxLength = 64 << 20
+	// maxDepth is the maximum nesting of decoded containers.
+	maxDepth = 64
+)
+
+var (
+	errBreak     = errors.New("cbor: unexpected break")
+	errMalformed = errors.New("cbor: malformed item")
+)
+
+// Field is a decoded field.
+//
+// The value is one of string, []byte, bool, int64, uint64, float64,
+// time.Time, []any, []Field for nested maps, or nil. Durations are
+// decoded as their integer nanoseconds.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Decoder decodes entries from a CBOR sequence.
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder returns a decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode decodes the next entry, returning io.EOF at the end of the stream.
+func (d *Decoder) Decode() ([]Field, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+
+	v, err := d.decode(0)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	fields, ok := v.([]Field)
+	if !ok {
+		return nil, fmt.Errorf("cbor: expected map, got %T", v)
+	}
+	return fields, nil
+}
+
+func (d *Decoder) decode(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errors.New("cbor: maximum nesting exceeded")
+	}
+
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if b == 0xff {
+		return nil, errBreak
+	}
+	major, info := b>>5, b&0x1f
+
+	if major == 7 {
+		return d.decodeSimple(info)
+	}
+
+	indefinite := info == 31
+	var n uint64
+	if !indefinite {
+		if n, err = d.readArg(info); err != nil {
+			return nil, err
+		}
+	}
+
+	switch major {
+	case 0:
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case 1:
+		if n > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(n), nil
+	case 2, 3:
+		p, err := d.readString(major, indefinite, n)
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(p), nil
+		}
+		return p, nil
+	case 4:
+		return d.decodeArray(indefinite, n, depth)
+	case 5:
+		return d.decodeMap(indefinite, n, depth)
+	default:
+		if indefinite {
+			return nil, errMalformed
+		}
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		switch n {
+		case 0:
+			return dateTime(v)
+		case 1:
+			return epochTime(v)
+		}
+		return v, nil
+	}
+}
+
+func (d *Decoder) decodeSimple(info byte) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		bits, err := d.readArg(25)
+		if err != nil {
+			return nil, err
+		}
+		return halfToFloat(uint16(bits)), nil
+	case 26:
+		bits, err := d.readArg(26)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(bits))), nil
+	case 27:
+		bits, err := d.readArg(27)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(bits), nil
+	default:
+		return nil, errMalformed
+	}
+}
+
+func (d *Decoder) decodeArray(indefinite bool, n uint64, depth int) (any, error) {
+	if !indefinite && n > maxLength {
+		return nil, errMalformed
+	}
+
+	vals := []any{}
+	for i := uint64(0); indefinite || i < n; i++ {
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			if indefinite && errors.Is(err, errBreak) {
+				break
+			}
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+func (d *Decoder) decodeMap(indefinite bool, n uint64, depth int) (any, error) {
+	if !indefinite && n > maxLength {
+		return nil, errMalformed
+	}
+
+	var fields []Field
+	for i := uint64(0); indefinite || i < n; i++ {
+		k, err := d.decode(depth + 1)
+		if err != nil {
+			if indefinite && errors.Is(err, errBreak) {
+				break
+			}
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+
+		v, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field{Key: key, Value: v})
+	}
+	return fields, nil
+}
+
+// readArg reads the argument of an item head with the additional info.
+func (d *Decoder) readArg(info byte) (uint64, error) {
+	if info < 24 {
+		return uint64(info), nil
+	}
+
+	var b [8]byte
+	switch info {
+	case 24:
+		v, err := d.r.ReadByte()
+		return uint64(v), err
+	case 25:
+		_, err := io.ReadFull(d.r, b[:2])
+		return uint64(binary.BigEndian.Uint16(b[:2])), err
+	case 26:
+		_, err := io.ReadFull(d.r, b[:4])
+		return uint64(binary.BigEndian.Uint32(b[:4])), err
+	case 27:
+		_, err := io.ReadFull(d.r, b[:])
+		return binary.BigEndian.Uint64(b[:]), err
+	default:
+		return 0, errMalformed
+	}
+}
+
+// readString reads a byte or text string, joining the chunks of an
+// indefinite length string.
+func (d *Decoder) readString(major byte, indefinite bool, n uint64) ([]byte, error) {
+	if !indefinite {
+		if n > maxLength {
+			return nil, errMalformed
+		}
+		p := make([]byte, n)
+		_, err := io.ReadFull(d.r, p)
+		return p, err
+	}
+
+	p := []byte{}
+	for {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0xff {
+			return p, nil
+		}
+		if b>>5 != major || b&0x1f == 31 {
+			return nil, errMalformed
+		}
+
+		n, err := d.readArg(b & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+		chunk, err := d.readString(major, false, n)
+		if err != nil {
+			return nil, err
+		}
+		if len(p)+len(chunk) > maxLength {
+			return nil, errMalformed
+		}
+		p = append(p, chunk...)
+	}
+}
+
+func dateTime(v any) (any, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.New("cbor: invalid date time")
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil, errors.New("cbor: invalid date time")
+	}
+	return t.UTC(), nil
+}
+
+func epochTime(v any) (any, error) {
+	switch v := v.(type) {
+	case int64:
+		return time.Unix(v, 0).UTC(), nil
+	case uint64:
+		return time.Unix(int64(min(v, math.MaxInt64)), 0).UTC(), nil
+	case float64:
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3).UTC(), nil
+	default:
+		return nil, errors.New("cbor: invalid epoch time")
+	}
+}
+
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
@@ -0,0 +1,147 @@
+package cbor_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/cbor"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_Decode(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.CBORFormat(), logger.Info).With(ctx.Str("app", "test"))
+
+	log.Info("first", ctx.Int("int", -5), ctx.Uint64("uint", math.MaxUint64), ctx.Float64("float", 1.5),
+		ctx.Bool("bool", true), ctx.Duration("dur", time.Second), ctx.Time("time", time.Unix(1541573670, 123456789)),
+		ctx.Bytes("bytes", []byte{1, 2}), ctx.Strs("strs", []string{"a", "b"}), ctx.Interface("nil", nil))
+	log.Error("second")
+
+	dec := cbor.NewDecoder(&buf)
+
+	got, err := dec.Decode()
+	require.NoError(t, err)
+	want := []cbor.Field{
+		{Key: "lvl", Value: "info"},
+		{Key: "msg", Value: "first"},
+		{Key: "app", Value: "test"},
+		{Key: "int", Value: int64(-5)},
+		{Key: "uint", Value: uint64(math.MaxUint64)},
+		{Key: "float", Value: 1.5},
+		{Key: "bool", Value: true},
+		{Key: "dur", Value: int64(time.Second)},
+		{Key: "time", Value: time.Unix(1541573670, 123456789).UTC()},
+		{Key: "bytes", Value: []byte{1, 2}},
+		{Key: "strs", Value: []any{"a", "b"}},
+		{Key: "nil", Value: nil},
+	}
+	assert.Equal(t, want, got)
+
+	got, err = dec.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, []cbor.Field{{Key: "lvl", Value: "eror"}, {Key: "msg", Value: "second"}, {Key: "app", Value: "test"}}, got)
+
+	_, err = dec.Decode()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestDecoder_DecodeDefiniteLength(t *testing.T) {
+	in := []byte{
+		0xa3,
+		0x61, 'a', 0x82, 0xf9, 0x3e, 0x00, 0xf9, 0xfc, 0x00,
+		0x61, 'b', 0x7f, 0x62, 'h', 'e', 0x63, 'l', 'l', 'o', 0xff,
+		0x61, 'c', 0xa1, 0x61, 'd', 0xd8, 0x20, 0x60,
+	}
+
+	got, err := cbor.NewDecoder(bytes.NewReader(in)).Decode()
+
+	require.NoError(t, err)
+	want := []cbor.Field{
+		{Key: "a", Value: []any{1.5, math.Inf(-1)}},
+		{Key: "b", Value: "hello"},
+		{Key: "c", Value: []cbor.Field{{Key: "d", Value: ""}}},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestDecoder_DecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+	}{
+		{
+			name: "not a map",
+			in:   []byte{0x01},
+		},
+		{
+			name: "truncated",
+			in:   []byte{0xbf, 0x61, 'a'},
+		},
+		{
+			name: "truncated string",
+			in:   []byte{0xbf, 0x63, 'a'},
+		},
+		{
+			name: "unexpected break",
+			in:   []byte{0xbf, 0x61, 'a', 0xff},
+		},
+		{
+			name: "invalid additional info",
+			in:   []byte{0xbf, 0x61, 'a', 0x1c, 0xff},
+		},
+		{
+			name: "invalid string chunk",
+			in:   []byte{0xbf, 0x61, 'a', 0x7f, 0x41, 'b', 0xff, 0xff},
+		},
+		{
+			name: "too long",
+			in:   []byte{0xbf, 0x61, 'a', 0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := cbor.NewDecoder(bytes.NewReader(test.in)).Decode()
+
+			assert.Error(t, err)
+			assert.NotErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.CBORFormat(), logger.Info)
+
+	log.Info("some <message>", ctx.Float64("nan", math.NaN()), ctx.Time("time", time.Unix(1541573670, 0)),
+		ctx.Bytes("bytes", []byte("hi")), ctx.Ints("ints", []int{1, 2}))
+	log.Warn("other", ctx.Float64("float", 1.25))
+
+	var sb strings.Builder
+	err := cbor.ToJSON(&sb, &buf)
+
+	require.NoError(t, err)
+	want := `{"lvl":"info","msg":"some <message>","nan":"NaN","time":"2018-11-07T06:54:30Z","bytes":"aGk=","ints":[1,2]}` + "\n" +
+		`{"lvl":"warn","msg":"other","float":1.25}` + "\n"
+	assert.Equal(t, want, sb.String())
+}
+
+func TestToJSON_Error(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.CBORFormat(), logger.Info)
+	log.Info("first")
+	buf.WriteByte(0x01)
+
+	var sb strings.Builder
+	err := cbor.ToJSON(&sb, &buf)
+
+	assert.Error(t, err)
+	assert.Equal(t, `{"lvl":"info","msg":"first"}`+"\n", sb.String())
+}
@@ -0,0 +1,105 @@
+package cbor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ToJSON converts the CBOR entries read from r to json lines written to w.
+//
+// Times are written in RFC3339 format, byte strings are base64 encoded and
+// non-finite floats are written as strings.
+func ToJSON(w io.Writer, r io.Reader) error {
+	bw := bufio.NewWriter(w)
+	dec := NewDecoder(r)
+	buf := &bytes.Buffer{}
+	for {
+		fields, err := dec.Decode()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return bw.Flush()
+			}
+			_ = bw.Flush()
+			return err
+		}
+
+		buf.Reset()
+		if err = appendObject(buf, fields); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		if _, err = bw.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+func appendObject(buf *bytes.Buffer, fields []Field) error {
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := appendJSON(buf, f.Key); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+		if err := appendValue(buf, f.Value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func appendValue(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case []Field:
+		return appendObject(buf, v)
+	case []any:
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := appendValue(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			buf.WriteString(`"NaN"`)
+		case math.IsInf(v, 1):
+			buf.WriteString(`"+Inf"`)
+		case math.IsInf(v, -1):
+			buf.WriteString(`"-Inf"`)
+		default:
+			buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		return nil
+	case time.Time:
+		return appendJSON(buf, v.Format(time.RFC3339Nano))
+	default:
+		return appendJSON(buf, v)
+	}
+}
+
+func appendJSON(buf *bytes.Buffer, v any) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	// Remove the line break written by the encoder.
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
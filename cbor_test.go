@@ -0,0 +1,166 @@
+package logger_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/internal/bytes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCBORFormat(t *testing.T) {
+	fmtr := logger.CBORFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendBeginMarker(buf)
+	fmtr.WriteMessage(buf, time.Unix(123, 0).UTC(), logger.Error, "msg")
+	fmtr.AppendKey(buf, "error")
+	fmtr.AppendString(buf, "err")
+	fmtr.AppendEndMarker(buf)
+	fmtr.AppendLineBreak(buf)
+
+	want := []byte{
+		0xbf,
+		0x62, 't', 's', 0xc1, 0x18, 0x7b,
+		0x63, 'l', 'v', 'l', 0x64, 'e', 'r', 'o', 'r',
+		0x63, 'm', 's', 'g', 0x63, 'm', 's', 'g',
+		0x65, 'e', 'r', 'r', 'o', 'r', 0x63, 'e', 'r', 'r',
+		0xff,
+	}
+	assert.Equal(t, want, buf.Bytes())
+}
+
+func TestCBORFormat_Array(t *testing.T) {
+	fmtr := logger.CBORFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendArrayStart(buf)
+	fmtr.AppendInt(buf, 1)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendInt(buf, 2)
+	fmtr.AppendArrayEnd(buf)
+
+	assert.Equal(t, []byte{0x9f, 0x01, 0x02, 0xff}, buf.Bytes())
+}
+
+func TestCBORFormat_Values(t *testing.T) {
+	tests := []struct {
+		name   string
+		append func(logger.Formatter, *bytes.Buffer)
+		want   []byte
+	}{
+		{
+			name:   "small int",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInt(buf, 10) },
+			want:   []byte{0x0a},
+		},
+		{
+			name:   "one byte int",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInt(buf, 100) },
+			want:   []byte{0x18, 0x64},
+		},
+		{
+			name:   "four byte int",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInt(buf, 1000000) },
+			want:   []byte{0x1a, 0x00, 0x0f, 0x42, 0x40},
+		},
+		{
+			name:   "negative int",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInt(buf, -1000) },
+			want:   []byte{0x39, 0x03, 0xe7},
+		},
+		{
+			name:   "min int",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInt(buf, math.MinInt64) },
+			want:   []byte{0x3b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		},
+		{
+			name:   "max uint",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendUint(buf, math.MaxUint64) },
+			want:   []byte{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		},
+		{
+			name:   "single precision float",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendFloat(buf, 100000.0) },
+			want:   []byte{0xfa, 0x47, 0xc3, 0x50, 0x00},
+		},
+		{
+			name:   "double precision float",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendFloat(buf, 1.1) },
+			want:   []byte{0xfb, 0x3f, 0xf1, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9a},
+		},
+		{
+			name:   "infinity",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendFloat(buf, math.Inf(-1)) },
+			want:   []byte{0xfa, 0xff, 0x80, 0x00, 0x00},
+		},
+		{
+			name:   "bool",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendBool(buf, true); f.AppendBool(buf, false) },
+			want:   []byte{0xf5, 0xf4},
+		},
+		{
+			name:   "string",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendString(buf, "ü") },
+			want:   []byte{0x62, 0xc3, 0xbc},
+		},
+		{
+			name:   "invalid utf8 string",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendString(buf, "a\xff") },
+			want:   []byte{0x64, 'a', 0xef, 0xbf, 0xbd},
+		},
+		{
+			name:   "bytes",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.(logger.BytesAppender).AppendBytes(buf, []byte{1, 2}) },
+			want:   []byte{0x42, 0x01, 0x02},
+		},
+		{
+			name:   "time",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendTime(buf, time.Unix(1363896240, 0)) },
+			want:   []byte{0xc1, 0x1a, 0x51, 0x4b, 0x67, 0xb0},
+		},
+		{
+			name:   "fractional time",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendTime(buf, time.Unix(1363896240, 123456789).UTC()) },
+			want:   append([]byte{0xc0, 0x78, 0x1e}, "2013-03-21T20:04:00.123456789Z"...),
+		},
+		{
+			name:   "duration",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendDuration(buf, time.Microsecond) },
+			want:   []byte{0x19, 0x03, 0xe8},
+		},
+		{
+			name:   "interface",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInterface(buf, struct{ A int }{A: 1}) },
+			want:   []byte{0x65, '{', 'A', ':', '1', '}'},
+		},
+		{
+			name:   "nil interface",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInterface(buf, nil) },
+			want:   []byte{0xf6},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(512)
+			test.append(logger.CBORFormat(), buf)
+
+			assert.Equal(t, test.want, buf.Bytes())
+		})
+	}
+}
+
+func TestCBORFormat_Bytes(t *testing.T) {
+	var sb strings.Builder
+	log := logger.New(&sb, logger.CBORFormat(), logger.Info)
+
+	log.Info("m", ctx.Bytes("b", []byte{0xff}))
+
+	want := "\xbf\x63lvl\x64info\x63msg\x61m\x61b\x41\xff\xff"
+	assert.Equal(t, want, sb.String())
+}
@@ -0,0 +1,244 @@
+package logger
+
+import (
+	stdbytes "bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hamba/logger/v2/internal/bytes"
+)
+
+// Google Cloud Logging special field keys.
+const (
+	gcpTraceKey          = "logging.googleapis.com/trace"
+	gcpSpanIDKey         = "logging.googleapis.com/spanId"
+	gcpTraceSampledKey   = "logging.googleapis.com/trace_sampled"
+	gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+	gcpHTTPRequestKey    = "httpRequest"
+)
+
+// WithTraceFields sets the keys of the trace fields the gcp and aws
+// formatters map onto their trace fields. It defaults to DefaultTraceKeys
+// and should match the keys set with Logger.WithTraceKeys.
+func WithTraceFields(keys TraceKeys) FormatOption {
+	return func(c *formatConfig) {
+		c.traceKeys = keys
+	}
+}
+
+type gcp struct {
+	json
+
+	project string
+}
+
+// GCPFormat formats a log line in the Google Cloud Logging structured
+// JSON format.
+//
+// The level is written as the severity and the well known keys are
+// mapped onto the special Cloud Logging fields: the trace ID onto the trace,
+// prefixed with the project when one is given, the span ID onto the span ID,
+// the trace flags or "trace_sampled" onto the trace sampling decision and
+// "caller" onto the source location. The trace keys are set with
+// WithTraceFields. Keys prefixed with "httpRequest." are nested into the
+// httpRequest object.
+func GCPFormat(project string, opts ...FormatOption) Formatter {
+	return &gcp{json: json{cfg: newFormatConfig(opts)}, project: project}
+}
+
+func (g *gcp) WriteMessage(buf *bytes.Buffer, ts time.Time, lvl Level, msg string) {
+	if !ts.IsZero() {
+		buf.WriteString(`"time":`)
+		g.AppendTime(buf, ts)
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"severity":"`)
+	buf.WriteString(gcpSeverity(lvl))
+	buf.WriteString(`","message":`)
+	appendString(buf, msg, true)
+}
+
+// Prefixes of the quoted keys nested by the gcp formatter.
+var (
+	gcpHTTPRequestPrefix    = []byte(`"` + gcpHTTPRequestKey + `.`)
+	gcpSourceLocationPrefix = []byte(`"` + gcpSourceLocationKey + `.`)
+)
+
+func (g *gcp) AppendEndMarker(buf *bytes.Buffer) {
+	buf.WriteByte('}')
+
+	// Only entries with nested keys are rewritten.
+	b := buf.Bytes()
+	if stdbytes.Contains(b, gcpHTTPRequestPrefix) || stdbytes.Contains(b, gcpSourceLocationPrefix) {
+		nestJSON(buf, splitGCP)
+	}
+}
+
+func (g *gcp) AppendKey(buf *bytes.Buffer, key string) {
+	keys := g.cfg.traceKeys
+	switch {
+	case key == "":
+	case key == keys.TraceID:
+		key = gcpTraceKey
+	case key == keys.SpanID:
+		key = gcpSpanIDKey
+	case key == keys.TraceFlags, key == "trace_sampled":
+		key = gcpTraceSampledKey
+	case key == "caller":
+		key = gcpSourceLocationKey + ".file"
+	}
+
+	g.json.AppendKey(buf, key)
+}
+
+func (g *gcp) AppendString(buf *bytes.Buffer, s string) {
+	b := buf.Bytes()
+	switch {
+	case g.project != "" && hasSuffix(b, `"`+gcpTraceKey+`":`):
+		buf.WriteString(`"projects/`)
+		appendString(buf, g.project, false)
+		buf.WriteString(`/traces/`)
+		appendString(buf, s, false)
+		buf.WriteByte('"')
+		return
+	case hasSuffix(b, `"`+gcpTraceSampledKey+`":`):
+		// Trace flags are hex encoded, the lowest bit being the sampled flag.
+		if flags, err := strconv.ParseUint(s, 16, 8); err == nil {
+			buf.AppendBool(flags&1 == 1)
+			return
+		}
+	case hasSuffix(b, `"`+gcpSourceLocationKey+`.file":`):
+		// The line is a string as it is an int64 in the protobuf JSON mapping.
+		if i := strings.LastIndexByte(s, ':'); i > 0 && isDigits(s[i+1:]) {
+			appendString(buf, s[:i], true)
+			buf.WriteString(`,"` + gcpSourceLocationKey + `.line":"`)
+			buf.WriteString(s[i+1:])
+			buf.WriteByte('"')
+			return
+		}
+	}
+
+	appendString(buf, s, true)
+}
+
+func (g *gcp) AppendTime(buf *bytes.Buffer, t time.Time) {
+	buf.WriteByte('"')
+	buf.AppendTime(t, timeFormatNano)
+	buf.WriteByte('"')
+}
+
+func (g *gcp) AppendDuration(buf *bytes.Buffer, d time.Duration) {
+	// Durations are written in the protobuf JSON mapping, e.g. "1.5s".
+	buf.WriteByte('"')
+	buf.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64))
+	buf.WriteString(`s"`)
+}
+
+func splitGCP(key []byte) (name, rest []byte, ok bool) {
+	for _, prefix := range []string{gcpHTTPRequestKey, gcpSourceLocationKey} {
+		if len(key) > len(prefix) && key[len(prefix)] == '.' && string(key[:len(prefix)]) == prefix {
+			return key[:len(prefix)], key[len(prefix)+1:], true
+		}
+	}
+	return nil, nil, false
+}
+
+func gcpSeverity(lvl Level) string {
+	switch lvl {
+	case Trace, Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warn:
+		return "WARNING"
+	case Error:
+		return "ERROR"
+	case Crit:
+		return "CRITICAL"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// AWS Lambda field keys.
+const (
+	awsTraceKey = "xray_trace_id"
+)
+
+type aws struct {
+	json
+}
+
+// AWSFormat formats a log line in the AWS Lambda structured JSON format.
+//
+// The timestamp is written in RFC3339 with milliseconds, the level in upper
+// case and the trace ID is mapped onto the X-Ray trace ID, converting
+// OpenTelemetry trace IDs into the X-Ray format. The trace keys are set
+// with WithTraceFields.
+//
+// Entries are plain structured logs, the CloudWatch embedded metric format
+// is not written.
+func AWSFormat(opts ...FormatOption) Formatter {
+	return &aws{json: json{cfg: newFormatConfig(opts)}}
+}
+
+func (a *aws) WriteMessage(buf *bytes.Buffer, ts time.Time, lvl Level, msg string) {
+	if !ts.IsZero() {
+		buf.WriteString(`"timestamp":`)
+		a.AppendTime(buf, ts)
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"level":"`)
+	buf.WriteString(awsLevel(lvl))
+	buf.WriteString(`","message":`)
+	appendString(buf, msg, true)
+}
+
+func (a *aws) AppendKey(buf *bytes.Buffer, key string) {
+	if key != "" && key == a.cfg.traceKeys.TraceID {
+		key = awsTraceKey
+	}
+
+	a.json.AppendKey(buf, key)
+}
+
+func (a *aws) AppendString(buf *bytes.Buffer, s string) {
+	// X-Ray trace IDs are the OpenTelemetry trace ID split after the
+	// epoch seconds, e.g. 1-5759e988-bd862e3fe1be46a994272793.
+	if len(s) == 32 && hasSuffix(buf.Bytes(), `"`+awsTraceKey+`":`) {
+		buf.WriteString(`"1-`)
+		appendString(buf, s[:8], false)
+		buf.WriteByte('-')
+		appendString(buf, s[8:], false)
+		buf.WriteByte('"')
+		return
+	}
+
+	appendString(buf, s, true)
+}
+
+func (a *aws) AppendTime(buf *bytes.Buffer, t time.Time) {
+	buf.WriteByte('"')
+	buf.AppendTime(t, timeFormatMilli)
+	buf.WriteByte('"')
+}
+
+func awsLevel(lvl Level) string {
+	switch lvl {
+	case Trace:
+		return "TRACE"
+	case Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warn:
+		return "WARN"
+	case Error:
+		return "ERROR"
+	case Crit:
+		return "FATAL"
+	default:
+		return "UNKNOWN"
+	}
+}
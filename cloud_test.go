@@ -0,0 +1,154 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	ibytes "github.com/hamba/logger/v2/internal/bytes"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestGCPFormat(t *testing.T) {
+	fmtr := logger.GCPFormat("my-project")
+
+	buf := ibytes.NewBuffer(512)
+	fmtr.AppendBeginMarker(buf)
+	fmtr.WriteMessage(buf, time.Unix(123, 456).UTC(), logger.Warn, "some message")
+	fmtr.AppendKey(buf, "error")
+	fmtr.AppendString(buf, "some error")
+	fmtr.AppendEndMarker(buf)
+	fmtr.AppendLineBreak(buf)
+
+	want := `{"time":"1970-01-01T00:02:03.000000456Z","severity":"WARNING","message":"some message","error":"some error"}` + "\n"
+	assert.Equal(t, want, string(buf.Bytes()))
+}
+
+func TestGCPFormat_Severities(t *testing.T) {
+	tests := []struct {
+		lvl  logger.Level
+		want string
+	}{
+		{lvl: logger.Crit, want: "CRITICAL"},
+		{lvl: logger.Error, want: "ERROR"},
+		{lvl: logger.Warn, want: "WARNING"},
+		{lvl: logger.Info, want: "INFO"},
+		{lvl: logger.Debug, want: "DEBUG"},
+		{lvl: logger.Trace, want: "DEBUG"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.lvl.String(), func(t *testing.T) {
+			fmtr := logger.GCPFormat("")
+
+			buf := ibytes.NewBuffer(512)
+			fmtr.WriteMessage(buf, time.Time{}, test.lvl, "msg")
+
+			assert.Equal(t, `"severity":"`+test.want+`","message":"msg"`, string(buf.Bytes()))
+		})
+	}
+}
+
+func TestGCPFormat_SpecialFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.GCPFormat("my-project"), logger.Info)
+
+	span := &fakeSpan{ID: byte(2), Recording: true}
+	log.Info("some message",
+		ctx.TraceID("trace_id", span),
+		ctx.Str("span_id", "0200000000000000"),
+		ctx.Bool("trace_sampled", true),
+		ctx.Str("caller", "/src/main.go:42"),
+		ctx.Str("httpRequest.requestMethod", "GET"),
+		ctx.Int("httpRequest.status", 200),
+		ctx.Duration("httpRequest.latency", 1500*time.Millisecond),
+		ctx.Str("user.id", "bob"),
+	)
+
+	want := `{"severity":"INFO","message":"some message",` +
+		`"logging.googleapis.com/trace":"projects/my-project/traces/01000000000000000000000000000000",` +
+		`"logging.googleapis.com/spanId":"0200000000000000",` +
+		`"logging.googleapis.com/trace_sampled":true,` +
+		`"logging.googleapis.com/sourceLocation":{"file":"/src/main.go","line":"42"},` +
+		`"httpRequest":{"requestMethod":"GET","status":200,"latency":"1.5s"},` +
+		`"user.id":"bob"}` + "\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestGCPFormat_TraceFlags(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.GCPFormat(""), logger.Info)
+
+	log.Info("some message", ctx.Str("trace_flags", "01"), ctx.Str("trace_flags", "00"))
+
+	want := `{"severity":"INFO","message":"some message","logging.googleapis.com/trace_sampled":true,"logging.googleapis.com/trace_sampled":false}` + "\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestGCPFormat_TraceWithoutProject(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.GCPFormat(""), logger.Info)
+
+	log.Info("some message", ctx.Str("trace_id", "abc"))
+
+	want := `{"severity":"INFO","message":"some message","logging.googleapis.com/trace":"abc"}` + "\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestAWSFormat(t *testing.T) {
+	fmtr := logger.AWSFormat()
+
+	buf := ibytes.NewBuffer(512)
+	fmtr.AppendBeginMarker(buf)
+	fmtr.WriteMessage(buf, time.Unix(123, 456e6).UTC(), logger.Crit, "some message")
+	fmtr.AppendKey(buf, "error")
+	fmtr.AppendString(buf, "some error")
+	fmtr.AppendEndMarker(buf)
+	fmtr.AppendLineBreak(buf)
+
+	want := `{"timestamp":"1970-01-01T00:02:03.456Z","level":"FATAL","message":"some message","error":"some error"}` + "\n"
+	assert.Equal(t, want, string(buf.Bytes()))
+}
+
+func TestAWSFormat_TraceID(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.AWSFormat(), logger.Info)
+
+	log.Info("some message",
+		ctx.Str("trace_id", "5759e988bd862e3fe1be46a994272793"),
+		ctx.Time("time", time.Unix(123, 0).UTC()),
+	)
+
+	want := `{"level":"INFO","message":"some message","xray_trace_id":"1-5759e988-bd862e3fe1be46a994272793","time":"1970-01-01T00:02:03.000Z"}` + "\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestAWSFormat_TraceFields(t *testing.T) {
+	keys := logger.TraceKeys{TraceID: "trace.id", SpanID: "span.id", TraceFlags: "trace.flags"}
+	goCtx := trace.ContextWithSpanContext(context.Background(), newSpanContext())
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.AWSFormat(logger.WithTraceFields(keys)), logger.Info).WithTraceKeys(keys)
+
+	log.Ctx(goCtx).Info("some message", ctx.Str("trace_id", "other"))
+
+	want := `{"level":"INFO","message":"some message","xray_trace_id":"1-01000000-000000000000000000000000","span.id":"0200000000000000","trace.flags":"01","trace_id":"other"}` + "\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestGCPFormat_TraceFields(t *testing.T) {
+	keys := logger.TraceKeys{TraceID: "trace.id", SpanID: "span.id", TraceFlags: "trace.flags"}
+	goCtx := trace.ContextWithSpanContext(context.Background(), newSpanContext())
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.GCPFormat("my-project", logger.WithTraceFields(keys)), logger.Info).WithTraceKeys(keys)
+
+	log.Ctx(goCtx).Info("some message")
+
+	want := `{"severity":"INFO","message":"some message","logging.googleapis.com/trace":"projects/my-project/traces/01000000000000000000000000000000","logging.googleapis.com/spanId":"0200000000000000","logging.googleapis.com/trace_sampled":true}` + "\n"
+	assert.Equal(t, want, buf.String())
+}
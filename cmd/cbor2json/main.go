@@ -0,0 +1,68 @@
+// Command cbor2json converts logs written in the CBOR format to JSON lines.
+//
+// Usage:
+//
+//	cbor2json [file ...]
+//
+// Entries are read from the files, or stdin if none are given.
+//
+//	cbor2json app.log | logpretty
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hamba/logger/v2/cbor"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("cbor2json", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "Usage: cbor2json [file ...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, name := range files {
+		if err := convertFile(name, stdin, stdout); err != nil {
+			_, _ = fmt.Fprintf(stderr, "cbor2json: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+func convertFile(name string, stdin io.Reader, w io.Writer) error {
+	if name == "-" {
+		return cbor.ToJSON(w, stdin)
+	}
+
+	f, err := os.Open(name) //nolint:gosec // Reading user given files is intended.
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	if err = cbor.ToJSON(w, f); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	var in bytes.Buffer
+	log := logger.New(&in, logger.CBORFormat(), logger.Info)
+	log.Info("started", ctx.Int("port", 8080))
+	log.Error("failed", ctx.Str("err", "some error"))
+
+	var stdout, stderr bytes.Buffer
+	code := run(nil, &in, &stdout, &stderr)
+
+	assert.Equal(t, 0, code)
+	want := `{"lvl":"info","msg":"started","port":8080}` + "\n" +
+		`{"lvl":"eror","msg":"failed","err":"some error"}` + "\n"
+	assert.Equal(t, want, stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestRun_Files(t *testing.T) {
+	var in bytes.Buffer
+	log := logger.New(&in, logger.CBORFormat(), logger.Info)
+	log.Info("started")
+
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	require.NoError(t, os.WriteFile(name, in.Bytes(), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{name, name}, strings.NewReader(""), &stdout, &stderr)
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, strings.Repeat(`{"lvl":"info","msg":"started"}`+"\n", 2), stdout.String())
+}
+
+func TestRun_InvalidInput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(nil, strings.NewReader("\x01"), &stdout, &stderr)
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr.String(), "cbor2json: cbor:")
+}
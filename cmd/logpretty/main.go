@@ -0,0 +1,211 @@
+// Command logpretty renders JSON and logfmt logs in the console format.
+//
+// Usage:
+//
+//	logpretty [flags] [file ...]
+//
+// Lines are read from the files, or stdin if none are given, detecting
+// the format of each line. Lines that cannot be parsed are written as is.
+//
+//	kubectl logs my-pod | logpretty -level warn -exclude 'k8s.*'
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/internal/bytes"
+	"github.com/hamba/logger/v2/internal/render"
+	"github.com/hamba/logger/v2/parser"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type config struct {
+	lvl         logger.Level
+	include     []string
+	exclude     []string
+	loc         *time.Location
+	timeLayouts []string
+	timeFormat  string
+	color       logger.FormatOption
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	cfg, files, err := parseFlags(args, stdout, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	p := &printer{
+		cfg:  cfg,
+		fmtr: logger.ConsoleFormat(cfg.color, logger.WithTimeLayout(cfg.timeFormat)),
+		buf:  bytes.NewBuffer(512),
+		w:    stdout,
+	}
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, name := range files {
+		if err = p.printFile(name, stdin); err != nil {
+			_, _ = fmt.Fprintf(stderr, "logpretty: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseFlags(args []string, stdout, stderr io.Writer) (config, []string, error) {
+	fs := flag.NewFlagSet("logpretty", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "Usage: logpretty [flags] [file ...]")
+		fs.PrintDefaults()
+	}
+
+	lvl := fs.String("level", "trace", "The minimum level of entries to print")
+	include := fs.String("include", "", "Comma separated keys of fields to print, which may be glob patterns")
+	exclude := fs.String("exclude", "", "Comma separated keys of fields to omit, which may be glob patterns")
+	tz := fs.String("tz", "Local", "The time zone to print times in")
+	timeLayout := fs.String("time-layout", "", "The layout of times in the input, detected from the logger time formats if empty; unix times are always read")
+	timeFormat := fs.String("time-format", time.Kitchen, "The layout to print times with")
+	color := fs.String("color", "auto", "When to write colors, one of auto, always or never")
+	if err := fs.Parse(args); err != nil {
+		return config{}, nil, err
+	}
+
+	cfg := config{
+		include:     splitKeys(*include),
+		exclude:     splitKeys(*exclude),
+		timeLayouts: parser.TimeFormats(*timeLayout),
+		timeFormat:  *timeFormat,
+	}
+
+	var err error
+	cfg.color, err = colorOption(*color, stdout)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "logpretty: %v\n", err)
+		return config{}, nil, err
+	}
+	cfg.lvl, err = logger.LevelFromString(*lvl)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "logpretty: %v\n", err)
+		return config{}, nil, err
+	}
+	cfg.loc, err = time.LoadLocation(*tz)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "logpretty: %v\n", err)
+		return config{}, nil, err
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+func colorOption(when string, w io.Writer) (logger.FormatOption, error) {
+	switch when {
+	case "auto":
+		return logger.WithColorAuto(w), nil
+	case "always":
+		return logger.WithColor(true), nil
+	case "never":
+		return logger.WithColor(false), nil
+	default:
+		return nil, fmt.Errorf("unknown color mode %q", when)
+	}
+}
+
+func splitKeys(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+type printer struct {
+	cfg  config
+	fmtr logger.Formatter
+	buf  *bytes.Buffer
+	w    io.Writer
+}
+
+func (p *printer) printFile(name string, stdin io.Reader) error {
+	if name == "-" {
+		return p.print(stdin)
+	}
+
+	f, err := os.Open(name) //nolint:gosec // Reading user given files is intended.
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	return p.print(f)
+}
+
+func (p *printer) print(r io.Reader) error {
+	s := parser.NewScanner(r, parser.WithTimeFormats(p.cfg.timeLayouts...))
+	for s.Scan() {
+		p.buf.Reset()
+
+		rec, err := s.Record()
+		if err != nil {
+			p.buf.Write(s.Bytes())
+			p.buf.WriteByte('\n')
+		} else {
+			if rec.Level > p.cfg.lvl {
+				continue
+			}
+			render.Append(p.buf, p.fmtr, p.filter(rec))
+		}
+
+		if _, err = p.w.Write(p.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+// filter removes the excluded fields and converts times to the time zone.
+func (p *printer) filter(rec parser.Record) parser.Record {
+	if !rec.Time.IsZero() {
+		rec.Time = rec.Time.In(p.cfg.loc)
+	}
+
+	fields := rec.Fields[:0]
+	for _, f := range rec.Fields {
+		if len(p.cfg.include) > 0 && !matchKey(p.cfg.include, f.Key) {
+			continue
+		}
+		if matchKey(p.cfg.exclude, f.Key) {
+			continue
+		}
+		if t, ok := f.Value.(time.Time); ok {
+			f.Value = t.In(p.cfg.loc)
+		}
+		fields = append(fields, f)
+	}
+	rec.Fields = fields
+	return rec
+}
+
+func matchKey(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const input = `{"ts":"2021-01-02T03:04:05Z","lvl":"info","msg":"started","port":8080,"k8s.pod":"api-1"}
+ts=1609556645 lvl=eror msg="request failed" err="some error" dur=1.5s
+panic: something went wrong
+{"lvl":"dbug","msg":"details","k8s.pod":"api-1"}
+`
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "all entries",
+			args: []string{"-color", "never", "-tz", "UTC", "-time-format", "15:04:05"},
+			want: "03:04:05 INFO started port=8080 k8s.pod=api-1\n" +
+				"03:04:05 EROR request failed err=some error dur=1.5s\n" +
+				"panic: something went wrong\n" +
+				"DBUG details k8s.pod=api-1\n",
+		},
+		{
+			name: "level",
+			args: []string{"-color", "never", "-tz", "UTC", "-level", "info"},
+			want: "3:04AM INFO started port=8080 k8s.pod=api-1\n" +
+				"3:04AM EROR request failed err=some error dur=1.5s\n" +
+				"panic: something went wrong\n",
+		},
+		{
+			name: "include",
+			args: []string{"-color", "never", "-tz", "UTC", "-include", "port,err"},
+			want: "3:04AM INFO started port=8080\n" +
+				"3:04AM EROR request failed err=some error\n" +
+				"panic: something went wrong\n" +
+				"DBUG details\n",
+		},
+		{
+			name: "exclude",
+			args: []string{"-color", "never", "-tz", "UTC", "-exclude", "k8s.*"},
+			want: "3:04AM INFO started port=8080\n" +
+				"3:04AM EROR request failed err=some error dur=1.5s\n" +
+				"panic: something went wrong\n" +
+				"DBUG details\n",
+		},
+		{
+			name: "time zone",
+			args: []string{"-color", "never", "-tz", "Asia/Tokyo", "-level", "info", "-include", "none"},
+			want: "12:04PM INFO started\n" +
+				"12:04PM EROR request failed\n" +
+				"panic: something went wrong\n",
+		},
+		{
+			name: "passthrough only",
+			args: []string{"-tz", "UTC", "-level", "crit"},
+			want: "panic: something went wrong\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := run(test.args, strings.NewReader(input), &stdout, &stderr)
+
+			assert.Equal(t, 0, code)
+			assert.Equal(t, test.want, stdout.String())
+			assert.Empty(t, stderr.String())
+		})
+	}
+}
+
+func TestRun_TimeLayouts(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		in   string
+		want string
+	}{
+		{
+			name: "rfc3339 milli",
+			in:   `{"ts":"2021-01-02T03:04:05.123Z","lvl":"info","msg":"m"}`,
+			want: "03:04:05 INFO m\n",
+		},
+		{
+			name: "iso8601",
+			in:   `ts=2021-01-02T03:04:05+0000 lvl=info msg=m`,
+			want: "03:04:05 INFO m\n",
+		},
+		{
+			name: "layout",
+			args: []string{"-time-layout", "02/01/2006 15:04"},
+			in:   `{"ts":"02/01/2021 03:04","lvl":"info","msg":"m"}`,
+			want: "03:04:00 INFO m\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			args := append([]string{"-color", "never", "-tz", "UTC", "-time-format", "15:04:05"}, test.args...)
+			code := run(args, strings.NewReader(test.in+"\n"), &stdout, &stderr)
+
+			assert.Equal(t, 0, code)
+			assert.Equal(t, test.want, stdout.String())
+		})
+	}
+}
+
+func TestRun_Color(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := run([]string{"-color", "always"}, strings.NewReader(`{"lvl":"warn","msg":"some message","key":1}`), &stdout, &stderr)
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "\x1b[33mWARN\x1b[0m some message \x1b[36mkey=\x1b[0m1\n", stdout.String())
+}
+
+func TestRun_Files(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.log")
+	b := filepath.Join(dir, "b.log")
+	require.NoError(t, os.WriteFile(a, []byte("lvl=info msg=a\n"), 0o600))
+	require.NoError(t, os.WriteFile(b, []byte("lvl=info msg=b\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-color", "never", a, "-", b}, strings.NewReader("lvl=info msg=stdin\n"), &stdout, &stderr)
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "INFO a\nINFO stdin\nINFO b\n", stdout.String())
+}
+
+func TestRun_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "invalid level",
+			args:     []string{"-level", "loud"},
+			wantCode: 2,
+			wantErr:  "unknown level loud",
+		},
+		{
+			name:     "invalid time zone",
+			args:     []string{"-tz", "Nowhere/Special"},
+			wantCode: 2,
+			wantErr:  "unknown time zone",
+		},
+		{
+			name:     "invalid color mode",
+			args:     []string{"-color", "sometimes"},
+			wantCode: 2,
+			wantErr:  `unknown color mode "sometimes"`,
+		},
+		{
+			name:     "missing file",
+			args:     []string{filepath.Join(t.TempDir(), "missing.log")},
+			wantCode: 1,
+			wantErr:  "no such file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := run(test.args, strings.NewReader(""), &stdout, &stderr)
+
+			assert.Equal(t, test.wantCode, code)
+			assert.Contains(t, stderr.String(), test.wantErr)
+		})
+	}
+}
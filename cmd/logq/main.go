@@ -0,0 +1,248 @@
+// Command logq filters and aggregates JSON and logfmt logs.
+//
+// Usage:
+//
+//	logq [flags] [expression] [file ...]
+//
+// Lines are read from the files, or stdin if none are given, detecting
+// the format of each line. Entries matching the expression are written in
+// the output format, lines that cannot be parsed are skipped. Journald is
+// not an output format, as journald entries are sent to its socket rather
+// than written to a stream.
+//
+// Expressions compare fields to values with ==, !=, <, <=, >, >=, ~ and !~,
+// the latter matching regular expressions, combining them with &&, || and
+// !. The ts, lvl and msg keys refer to the timestamp, level and message,
+// with levels compared by severity. A field without a comparison matches
+// entries that have the field.
+//
+//	logq 'lvl>=warn && status>=500 && path~"^/api/"' app.log
+//	logq -count-by path 'status>=500' app.log
+//	logq -quantiles duration app.log
+//
+// Quantiles are computed separately for duration and numeric values of
+// the field, writing a line for each.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/internal/bytes"
+	"github.com/hamba/logger/v2/internal/render"
+	"github.com/hamba/logger/v2/parser"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+
+	os.Exit(code)
+}
+
+type config struct {
+	query       query
+	fmtr        logger.Formatter
+	timeLayouts []string
+	follow      bool
+	interval    time.Duration
+	countBy     string
+	quantiles   string
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	cfg, files, err := parseFlags(args, stdout, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		_, _ = fmt.Fprintf(stderr, "logq: %v\n", err)
+		return 2
+	}
+
+	q := &querier{
+		ctx: ctx,
+		cfg: cfg,
+		buf: bytes.NewBuffer(512),
+		w:   stdout,
+	}
+	switch {
+	case cfg.countBy != "":
+		q.counter = newCounter(cfg.countBy)
+	case cfg.quantiles != "":
+		q.quantiles = newQuantiles(cfg.quantiles)
+	}
+
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, name := range files {
+		if err = q.queryFile(name, stdin); err != nil {
+			_, _ = fmt.Fprintf(stderr, "logq: %v\n", err)
+			return 1
+		}
+	}
+
+	if err = q.writeStats(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "logq: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func parseFlags(args []string, stdout, stderr io.Writer) (config, []string, error) {
+	fs := flag.NewFlagSet("logq", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "Usage: logq [flags] [expression] [file ...]")
+		fs.PrintDefaults()
+	}
+
+	format := fs.String("format", "logfmt", "The output format, one of json, logfmt, console, ecs, gcp, aws, gelf, cbor or msgpack")
+	color := fs.String("color", "auto", "When to write colors in the console format, one of auto, always or never")
+	timeLayout := fs.String("time-layout", "", "The layout of times in the input, detected from the logger time formats if empty; unix times are always read")
+	follow := fs.Bool("follow", false, "Wait for more lines at the end of files")
+	interval := fs.Duration("interval", 250*time.Millisecond, "The interval files are checked for more lines when following")
+	countBy := fs.String("count-by", "", "Count matching entries by the value of the field, instead of writing them")
+	quantiles := fs.String("quantiles", "", "Compute the p50 and p99 of the duration and numeric values of the field, instead of writing entries")
+	if err := fs.Parse(args); err != nil {
+		return config{}, nil, err
+	}
+
+	cfg := config{
+		timeLayouts: parser.TimeFormats(*timeLayout),
+		follow:      *follow,
+		interval:    *interval,
+		countBy:     *countBy,
+		quantiles:   *quantiles,
+	}
+
+	switch *format {
+	case "json":
+		cfg.fmtr = logger.JSONFormat()
+	case "logfmt":
+		cfg.fmtr = logger.LogfmtFormat()
+	case "console":
+		opt, err := colorOption(*color, stdout)
+		if err != nil {
+			return config{}, nil, err
+		}
+		cfg.fmtr = logger.ConsoleFormat(opt)
+	case "ecs":
+		cfg.fmtr = logger.ECSFormat()
+	case "gcp":
+		cfg.fmtr = logger.GCPFormat("")
+	case "aws":
+		cfg.fmtr = logger.AWSFormat()
+	case "gelf":
+		host, err := os.Hostname()
+		if err != nil {
+			return config{}, nil, err
+		}
+		cfg.fmtr = logger.GELFFormat(host)
+	case "cbor":
+		cfg.fmtr = logger.CBORFormat()
+	case "msgpack":
+		cfg.fmtr = logger.MsgPackFormat()
+	default:
+		return config{}, nil, fmt.Errorf("unknown format %q", *format)
+	}
+	if cfg.countBy != "" && cfg.quantiles != "" {
+		return config{}, nil, errors.New("only one of count-by and quantiles can be set")
+	}
+
+	files := fs.Args()
+	var expr string
+	if len(files) > 0 {
+		expr, files = files[0], files[1:]
+	}
+
+	var err error
+	if cfg.query, err = parseQuery(expr); err != nil {
+		return config{}, nil, err
+	}
+	return cfg, files, nil
+}
+
+func colorOption(when string, w io.Writer) (logger.FormatOption, error) {
+	switch when {
+	case "auto":
+		return logger.WithColorAuto(w), nil
+	case "always":
+		return logger.WithColor(true), nil
+	case "never":
+		return logger.WithColor(false), nil
+	default:
+		return nil, fmt.Errorf("unknown color mode %q", when)
+	}
+}
+
+type querier struct {
+	ctx context.Context
+	cfg config
+	buf *bytes.Buffer
+	w   io.Writer
+
+	counter   *counter
+	quantiles *quantiles
+}
+
+func (q *querier) queryFile(name string, stdin io.Reader) error {
+	if name == "-" {
+		return q.query(stdin)
+	}
+
+	f, err := os.Open(name) //nolint:gosec // Reading user given files is intended.
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	var r io.Reader = f
+	if q.cfg.follow {
+		r = &followReader{ctx: q.ctx, r: f, interval: q.cfg.interval}
+	}
+	return q.query(r)
+}
+
+func (q *querier) query(r io.Reader) error {
+	s := parser.NewScanner(r, parser.WithTimeFormats(q.cfg.timeLayouts...))
+	for s.Scan() {
+		rec, err := s.Record()
+		if err != nil || !q.cfg.query.match(rec) {
+			continue
+		}
+
+		switch {
+		case q.counter != nil:
+			q.counter.add(rec)
+		case q.quantiles != nil:
+			q.quantiles.add(rec)
+		default:
+			q.buf.Reset()
+			render.Append(q.buf, q.cfg.fmtr, rec)
+			if _, err = q.w.Write(q.buf.Bytes()); err != nil {
+				return err
+			}
+		}
+	}
+	return s.Err()
+}
+
+func (q *querier) writeStats() error {
+	switch {
+	case q.counter != nil:
+		return q.counter.write(q.w)
+	case q.quantiles != nil:
+		return q.quantiles.write(q.w)
+	}
+	return nil
+}
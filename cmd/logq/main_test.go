@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const input = `lvl=info msg=request path=/api/users status=200 dur=10ms
+lvl=eror msg=request path=/api/users status=503 dur=1.2s
+not a log line
+{"lvl":"warn","msg":"request","path":"/health","status":404,"dur":"20ms"}
+lvl=eror msg=request path=/api/orders status=500 dur=300ms
+`
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "all entries",
+			args: nil,
+			want: "lvl=info msg=request path=/api/users status=200 dur=10ms\n" +
+				"lvl=eror msg=request path=/api/users status=503 dur=1.2s\n" +
+				"lvl=warn msg=request path=/health status=404 dur=20ms\n" +
+				"lvl=eror msg=request path=/api/orders status=500 dur=300ms\n",
+		},
+		{
+			name: "expression",
+			args: []string{`lvl>=warn && status>=500 && path~"/api/.*"`},
+			want: "lvl=eror msg=request path=/api/users status=503 dur=1.2s\n" +
+				"lvl=eror msg=request path=/api/orders status=500 dur=300ms\n",
+		},
+		{
+			name: "json",
+			args: []string{"-format", "json", "status==404"},
+			want: `{"lvl":"warn","msg":"request","path":"/health","status":404,"dur":"20ms"}` + "\n",
+		},
+		{
+			name: "ecs",
+			args: []string{"-format", "ecs", "status==404"},
+			want: `{"log":{"level":"warn"},"message":"request","ecs":{"version":"8.11.0"},"path":"/health","status":404,"dur":"20ms"}` + "\n",
+		},
+		{
+			name: "console",
+			args: []string{"-format", "console", "-color", "never", "status==404"},
+			want: "WARN request path=/health status=404 dur=20ms\n",
+		},
+		{
+			name: "count by",
+			args: []string{"-count-by", "path", "status>=400"},
+			want: "1\t/api/orders\n1\t/api/users\n1\t/health\n",
+		},
+		{
+			name: "count by level",
+			args: []string{"-count-by", "lvl"},
+			want: "2\teror\n1\tinfo\n1\twarn\n",
+		},
+		{
+			name: "duration quantiles",
+			args: []string{"-quantiles", "dur"},
+			want: "count=4 p50=20ms p99=1.2s\n",
+		},
+		{
+			name: "numeric quantiles",
+			args: []string{"-quantiles", "status", "lvl==eror"},
+			want: "count=2 p50=500 p99=503\n",
+		},
+		{
+			name: "empty quantiles",
+			args: []string{"-quantiles", "missing"},
+			want: "count=0 p50=- p99=-\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+
+			code := run(t.Context(), test.args, strings.NewReader(input), &stdout, &stderr)
+
+			assert.Equal(t, 0, code)
+			assert.Equal(t, test.want, stdout.String())
+			assert.Empty(t, stderr.String())
+		})
+	}
+}
+
+func TestRun_TimeLayouts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		in   string
+	}{
+		{
+			name: "rfc3339",
+			in:   "ts=2021-01-02T03:04:05.5Z lvl=info msg=m\n",
+		},
+		{
+			name: "iso8601",
+			in:   "ts=2021-01-02T03:04:05+0000 lvl=info msg=m\n",
+		},
+		{
+			name: "layout",
+			args: []string{"-time-layout", "02/01/2006 15:04"},
+			in:   `{"ts":"02/01/2021 03:04","lvl":"info","msg":"m"}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			args := append(test.args, `ts>="2021-01-02T03:00:00Z"`)
+
+			code := run(t.Context(), args, strings.NewReader(test.in), &stdout, &stderr)
+
+			assert.Equal(t, 0, code)
+			assert.Contains(t, stdout.String(), "msg=m")
+			assert.Empty(t, stderr.String())
+		})
+	}
+}
+
+func TestRun_MixedQuantiles(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	in := "lvl=info msg=a v=10ms\nlvl=info msg=b v=5\nlvl=info msg=c v=2s\n"
+
+	code := run(t.Context(), []string{"-quantiles", "v"}, strings.NewReader(in), &stdout, &stderr)
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "count=2 p50=10ms p99=2s\ncount=1 p50=5 p99=5\n", stdout.String())
+}
+
+func TestRun_Follow(t *testing.T) {
+	t.Parallel()
+
+	name := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(name, []byte("lvl=info msg=first\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	stdout := &syncBuffer{}
+	var stderr bytes.Buffer
+	done := make(chan int)
+	go func() {
+		done <- run(ctx, []string{"-follow", "-interval", "1ms", "", name}, nil, stdout, &stderr)
+	}()
+
+	assert.Eventually(t, func() bool { return stdout.String() == "lvl=info msg=first\n" }, time.Second, time.Millisecond)
+
+	f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString("lvl=info msg=second\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	assert.Eventually(t, func() bool { return strings.HasSuffix(stdout.String(), "msg=second\n") }, time.Second, time.Millisecond)
+
+	cancel()
+	assert.Equal(t, 0, <-done)
+}
+
+func TestRun_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "invalid expression",
+			args:     []string{"status>="},
+			wantCode: 2,
+			wantErr:  "expected value",
+		},
+		{
+			name:     "invalid format",
+			args:     []string{"-format", "xml"},
+			wantCode: 2,
+			wantErr:  `unknown format "xml"`,
+		},
+		{
+			name:     "both aggregations",
+			args:     []string{"-count-by", "a", "-quantiles", "b"},
+			wantCode: 2,
+			wantErr:  "only one of",
+		},
+		{
+			name:     "missing file",
+			args:     []string{"", filepath.Join(t.TempDir(), "missing.log")},
+			wantCode: 1,
+			wantErr:  "no such file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+
+			code := run(t.Context(), test.args, strings.NewReader(""), &stdout, &stderr)
+
+			assert.Equal(t, test.wantCode, code)
+			assert.Contains(t, stderr.String(), test.wantErr)
+		})
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/parser"
+)
+
+// query is a compiled filter expression.
+type query interface {
+	match(rec parser.Record) bool
+}
+
+type andQuery struct{ a, b query }
+
+func (q andQuery) match(rec parser.Record) bool { return q.a.match(rec) && q.b.match(rec) }
+
+type orQuery struct{ a, b query }
+
+func (q orQuery) match(rec parser.Record) bool { return q.a.match(rec) || q.b.match(rec) }
+
+type notQuery struct{ q query }
+
+func (q notQuery) match(rec parser.Record) bool { return !q.q.match(rec) }
+
+type matchAll struct{}
+
+func (matchAll) match(parser.Record) bool { return true }
+
+// existsQuery matches records with the field.
+type existsQuery struct{ key string }
+
+func (q existsQuery) match(rec parser.Record) bool {
+	_, ok := lookup(rec, q.key)
+	return ok
+}
+
+// cmpQuery compares a field to a literal. Records without the field
+// never match.
+type cmpQuery struct {
+	key string
+	op  string
+	lit literal
+	re  *regexp.Regexp
+}
+
+func (q cmpQuery) match(rec parser.Record) bool {
+	v, ok := lookup(rec, q.key)
+	if !ok {
+		return false
+	}
+
+	switch q.op {
+	case "~":
+		return q.re.MatchString(toString(v))
+	case "!~":
+		return !q.re.MatchString(toString(v))
+	}
+
+	c, ok := compare(v, q.lit)
+	if !ok {
+		return false
+	}
+	switch q.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+// lookup returns the value of a field, including the timestamp, level
+// and message of the record.
+func lookup(rec parser.Record, key string) (any, bool) {
+	switch key {
+	case logger.TimestampKey:
+		return rec.Time, !rec.Time.IsZero()
+	case logger.LevelKey:
+		return rec.Level, true
+	case logger.MessageKey:
+		return rec.Msg, true
+	}
+	return rec.Field(key)
+}
+
+// literal is a value in a filter expression.
+type literal struct {
+	text string
+	num  float64
+	dur  time.Duration
+
+	isNum, isDur bool
+}
+
+func newLiteral(s string, quoted bool) literal {
+	lit := literal{text: s}
+	if quoted {
+		return lit
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		lit.num, lit.isNum = f, true
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		lit.dur, lit.isDur = d, true
+	}
+	return lit
+}
+
+// compare compares a value to a literal, returning false if they cannot
+// be compared.
+func compare(v any, lit literal) (int, bool) {
+	switch v := v.(type) {
+	case logger.Level:
+		lvl, err := logger.LevelFromString(lit.text)
+		if err != nil {
+			return 0, false
+		}
+		// Levels are compared by severity, more severe levels are greater.
+		return cmpNum(float64(lvl), float64(v)), true
+	case int64:
+		return cmpNum(float64(v), lit.num), lit.isNum
+	case uint64:
+		return cmpNum(float64(v), lit.num), lit.isNum
+	case float64:
+		return cmpNum(v, lit.num), lit.isNum
+	case time.Duration:
+		return cmpNum(float64(v), float64(lit.dur)), lit.isDur
+	case time.Time:
+		t, err := time.Parse(time.RFC3339Nano, lit.text)
+		if err != nil {
+			return 0, false
+		}
+		return v.Compare(t), true
+	case bool:
+		b, err := strconv.ParseBool(lit.text)
+		if err != nil || b == v {
+			return 0, err == nil
+		}
+		return 1, true
+	default:
+		return strings.Compare(toString(v), lit.text), true
+	}
+}
+
+func cmpNum(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	case math.IsNaN(a) || math.IsNaN(b):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = toString(e)
+		}
+		return strings.Join(parts, ",")
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// parseQuery compiles a filter expression.
+//
+// Expressions compare fields to values with ==, !=, <, <=, >, >=, ~ and
+// !~, the latter matching regular expressions, and are combined with &&,
+// || and !. A field without a comparison matches if it exists.
+func parseQuery(s string) (query, error) {
+	if strings.TrimSpace(s) == "" {
+		return matchAll{}, nil
+	}
+
+	p := &queryParser{s: s}
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.i < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.i:])
+	}
+	return q, nil
+}
+
+type queryParser struct {
+	s string
+	i int
+}
+
+func (p *queryParser) parseOr() (query, error) {
+	q, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		q = orQuery{a: q, b: r}
+	}
+	return q, nil
+}
+
+func (p *queryParser) parseAnd() (query, error) {
+	q, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		q = andQuery{a: q, b: r}
+	}
+	return q, nil
+}
+
+func (p *queryParser) parseUnary() (query, error) {
+	switch {
+	case p.consume("!"):
+		q, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notQuery{q: q}, nil
+	case p.consume("("):
+		q, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return q, nil
+	}
+	return p.parseCmp()
+}
+
+var ops = []string{"==", "!=", "!~", "<=", ">=", "=", "<", ">", "~"}
+
+func (p *queryParser) parseCmp() (query, error) {
+	key := p.word()
+	if key == "" {
+		return nil, p.errorf("expected field")
+	}
+
+	var op string
+	for _, o := range ops {
+		if p.consume(o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		return existsQuery{key: key}, nil
+	}
+	if op == "=" {
+		op = "=="
+	}
+
+	val, quoted, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	q := cmpQuery{key: key, op: op, lit: newLiteral(val, quoted)}
+	if op == "~" || op == "!~" {
+		if q.re, err = regexp.Compile(val); err != nil {
+			return nil, p.errorf("invalid regular expression: %v", err)
+		}
+	}
+	return q, nil
+}
+
+func (p *queryParser) value() (string, bool, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) || p.s[p.i] != '"' {
+		val := p.word()
+		if val == "" {
+			return "", false, p.errorf("expected value")
+		}
+		return val, false, nil
+	}
+
+	start := p.i
+	for p.i++; p.i < len(p.s) && p.s[p.i] != '"'; p.i++ {
+		if p.s[p.i] == '\\' {
+			p.i++
+		}
+	}
+	if p.i >= len(p.s) {
+		return "", false, p.errorf("unterminated string")
+	}
+	p.i++
+
+	val, err := strconv.Unquote(p.s[start:p.i])
+	if err != nil {
+		return "", false, p.errorf("invalid string")
+	}
+	return val, true, nil
+}
+
+// word reads a field or bare value, ending at spaces, operators and
+// parentheses.
+func (p *queryParser) word() string {
+	p.skipSpace()
+	start := p.i
+	for p.i < len(p.s) && !strings.ContainsRune(" \t()=!<>~&|\"", rune(p.s[p.i])) {
+		p.i++
+	}
+	return p.s[start:p.i]
+}
+
+func (p *queryParser) consume(tok string) bool {
+	p.skipSpace()
+	if !strings.HasPrefix(p.s[p.i:], tok) {
+		return false
+	}
+	// Avoid reading "!=" and "!~" as a negation.
+	if tok == "!" && p.i+1 < len(p.s) && (p.s[p.i+1] == '=' || p.s[p.i+1] == '~') {
+		return false
+	}
+	p.i += len(tok)
+	return true
+}
+
+func (p *queryParser) skipSpace() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+func (p *queryParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("query: "+format+" at offset %d", append(args, p.i)...)
+}
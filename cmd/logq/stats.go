@@ -0,0 +1,131 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/hamba/logger/v2/parser"
+)
+
+// counter counts records by the value of a field.
+type counter struct {
+	key    string
+	counts map[string]int
+}
+
+func newCounter(key string) *counter {
+	return &counter{key: key, counts: map[string]int{}}
+}
+
+func (c *counter) add(rec parser.Record) {
+	v, ok := lookup(rec, c.key)
+	if !ok {
+		return
+	}
+	c.counts[toString(v)]++
+}
+
+// write writes the counts, most frequent first.
+func (c *counter) write(w io.Writer) error {
+	type count struct {
+		val string
+		n   int
+	}
+	counts := make([]count, 0, len(c.counts))
+	for val, n := range c.counts {
+		counts = append(counts, count{val: val, n: n})
+	}
+	slices.SortFunc(counts, func(a, b count) int {
+		if a.n != b.n {
+			return cmp.Compare(b.n, a.n)
+		}
+		return cmp.Compare(a.val, b.val)
+	})
+
+	for _, c := range counts {
+		if _, err := fmt.Fprintf(w, "%d\t%s\n", c.n, c.val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quantiles computes the p50 and p99 of a duration or numeric field.
+// Durations and numbers are kept in separate series, as they cannot be
+// compared.
+type quantiles struct {
+	key  string
+	durs []float64
+	nums []float64
+}
+
+func newQuantiles(key string) *quantiles {
+	return &quantiles{key: key}
+}
+
+func (q *quantiles) add(rec parser.Record) {
+	v, ok := lookup(rec, q.key)
+	if !ok {
+		return
+	}
+
+	switch v := v.(type) {
+	case time.Duration:
+		q.durs = append(q.durs, float64(v))
+	case int64:
+		q.nums = append(q.nums, float64(v))
+	case uint64:
+		q.nums = append(q.nums, float64(v))
+	case float64:
+		q.nums = append(q.nums, v)
+	}
+}
+
+// write writes a line for the durations and a line for the numbers,
+// omitting empty series unless both are empty.
+func (q *quantiles) write(w io.Writer) error {
+	if len(q.durs) > 0 || len(q.nums) == 0 {
+		if err := writeQuantiles(w, q.durs, formatDuration); err != nil {
+			return err
+		}
+	}
+	if len(q.nums) > 0 {
+		return writeQuantiles(w, q.nums, formatNumber)
+	}
+	return nil
+}
+
+func writeQuantiles(w io.Writer, vals []float64, format func(float64) string) error {
+	slices.Sort(vals)
+
+	_, err := fmt.Fprintf(w, "count=%d p50=%s p99=%s\n", len(vals), format(quantile(vals, 0.5)), format(quantile(vals, 0.99)))
+	return err
+}
+
+// quantile returns the nearest-rank quantile of the sorted values.
+func quantile(vals []float64, p float64) float64 {
+	if len(vals) == 0 {
+		return math.NaN()
+	}
+	i := int(math.Ceil(p*float64(len(vals)))) - 1
+	return vals[max(i, 0)]
+}
+
+func formatDuration(v float64) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+	return time.Duration(v).String()
+}
+
+func formatNumber(v float64) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
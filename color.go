@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hamba/logger/v2/internal/bytes"
+)
+
+// Color attributes.
+const (
+	ColorReset     = 0
+	ColorBold      = 1
+	ColorFaint     = 2
+	ColorUnderline = 4
+)
+
+// Foreground text colors.
+const (
+	ColorBlack = iota + 30
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
+
+// Color is a console color, made of ANSI SGR attributes,
+// e.g. Color{ColorRed, ColorBold}. An empty color writes no escape codes.
+type Color []int
+
+var resetColor = Color{ColorReset}
+
+func (c Color) write(buf *bytes.Buffer) {
+	buf.WriteByte('\x1b')
+	buf.WriteByte('[')
+	for i := range c {
+		if i > 0 {
+			buf.WriteByte(';')
+		}
+		buf.AppendInt(int64(c[i]))
+	}
+	buf.WriteByte('m')
+}
+
+func withColor(c Color, buf *bytes.Buffer, fn func()) {
+	c.write(buf)
+	fn()
+	resetColor.write(buf)
+}
+
+// Palette is the colors written by the console formatter.
+type Palette struct {
+	// Time is the color of the timestamp.
+	Time Color
+	// Levels are the colors of the levels.
+	Levels map[Level]Color
+	// Key is the color of keys.
+	Key Color
+	// ErrorKey is the color of keys starting with "err".
+	ErrorKey Color
+	// Keys are the colors of specific keys, taking precedence
+	// over Key and ErrorKey.
+	Keys map[string]Color
+}
+
+// DefaultPalette returns the default console palette.
+func DefaultPalette() Palette {
+	return Palette{
+		Time: Color{ColorBlue},
+		Levels: map[Level]Color{
+			Crit:  {ColorRed, ColorBold},
+			Error: {ColorRed},
+			Warn:  {ColorYellow},
+			Info:  {ColorGreen},
+			Debug: {ColorBlue},
+			Trace: {ColorWhite},
+		},
+		Key:      Color{ColorCyan},
+		ErrorKey: Color{ColorRed},
+	}
+}
+
+func (p Palette) keyColor(key string) Color {
+	if col, ok := p.Keys[key]; ok {
+		return col
+	}
+	if strings.HasPrefix(key, "err") {
+		return p.ErrorKey
+	}
+	return p.Key
+}
+
+type colorMode int
+
+const (
+	colorDefault colorMode = iota
+	colorAlways
+	colorNever
+	colorAuto
+)
+
+// WithColor sets whether the console formatter writes colors, regardless
+// of the environment.
+func WithColor(enabled bool) FormatOption {
+	return func(c *formatConfig) {
+		c.color = colorNever
+		if enabled {
+			c.color = colorAlways
+		}
+	}
+}
+
+// WithColorAuto makes the console formatter write colors only if w is a
+// terminal. Colors can be disabled with the NO_COLOR, or forced with the
+// FORCE_COLOR, environment variable.
+//
+// Only files, such as os.Stdout, are detected as terminals, on platforms
+// supporting termios and on Windows.
+func WithColorAuto(w io.Writer) FormatOption {
+	return func(c *formatConfig) {
+		c.color = colorAuto
+		c.colorOut = w
+	}
+}
+
+// WithPalette sets the colors written by the console formatter.
+func WithPalette(p Palette) FormatOption {
+	return func(c *formatConfig) {
+		c.palette = &p
+	}
+}
+
+// useColor reports whether colors should be written. The NO_COLOR and
+// FORCE_COLOR environment variables are only overridden by WithColor.
+func (c formatConfig) useColor() bool {
+	switch c.color {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("FORCE_COLOR") != "" {
+		return true
+	}
+	if c.color == colorAuto {
+		return isTerminal(c.colorOut)
+	}
+	return true
+}
@@ -0,0 +1,11 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+
+package logger
+
+import "io"
+
+// isTerminal reports false, as terminals are only detected with termios
+// and on Windows.
+func isTerminal(io.Writer) bool {
+	return false
+}
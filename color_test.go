@@ -0,0 +1,122 @@
+package logger_test
+
+import (
+	stdbytes "bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/internal/bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsoleFormat_WithPalette(t *testing.T) {
+	p := logger.DefaultPalette()
+	p.Time = nil
+	p.Levels[logger.Error] = logger.Color{logger.ColorMagenta, logger.ColorUnderline}
+	p.Keys = map[string]logger.Color{"user": {logger.ColorYellow}}
+	fmtr := logger.ConsoleFormat(logger.WithPalette(p))
+
+	buf := bytes.NewBuffer(512)
+	fmtr.WriteMessage(buf, time.Unix(123, 0).UTC(), logger.Error, "some message")
+	fmtr.AppendKey(buf, "user")
+	fmtr.AppendString(buf, "bob")
+	fmtr.AppendKey(buf, "err")
+	fmtr.AppendString(buf, "some error")
+
+	want := "12:02AM \x1b[35;4mEROR\x1b[0m some message \x1b[33muser=\x1b[0mbob \x1b[31merr=\x1b[0msome error"
+	assert.Equal(t, want, string(buf.Bytes()))
+}
+
+func TestConsoleFormat_Color(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "log"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = file.Close() })
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = devNull.Close() })
+
+	tests := []struct {
+		name       string
+		noColor    string
+		forceColor string
+		opts       []logger.FormatOption
+		want       bool
+	}{
+		{
+			name: "default",
+			want: true,
+		},
+		{
+			name:    "default with NO_COLOR",
+			noColor: "1",
+			want:    false,
+		},
+		{
+			name:       "default with NO_COLOR and FORCE_COLOR",
+			noColor:    "1",
+			forceColor: "1",
+			want:       false,
+		},
+		{
+			name:    "enabled with NO_COLOR",
+			noColor: "1",
+			opts:    []logger.FormatOption{logger.WithColor(true)},
+			want:    true,
+		},
+		{
+			name:       "disabled with FORCE_COLOR",
+			forceColor: "1",
+			opts:       []logger.FormatOption{logger.WithColor(false)},
+			want:       false,
+		},
+		{
+			name: "auto with buffer",
+			opts: []logger.FormatOption{logger.WithColorAuto(&stdbytes.Buffer{})},
+			want: false,
+		},
+		{
+			name: "auto with file",
+			opts: []logger.FormatOption{logger.WithColorAuto(file)},
+			want: false,
+		},
+		{
+			name: "auto with null device",
+			opts: []logger.FormatOption{logger.WithColorAuto(devNull)},
+			want: false,
+		},
+		{
+			name:       "auto with FORCE_COLOR",
+			forceColor: "1",
+			opts:       []logger.FormatOption{logger.WithColorAuto(&stdbytes.Buffer{})},
+			want:       true,
+		},
+		{
+			name:       "auto with NO_COLOR and FORCE_COLOR",
+			noColor:    "1",
+			forceColor: "1",
+			opts:       []logger.FormatOption{logger.WithColorAuto(&stdbytes.Buffer{})},
+			want:       false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", test.noColor)
+			t.Setenv("FORCE_COLOR", test.forceColor)
+			fmtr := logger.ConsoleFormat(test.opts...)
+
+			buf := bytes.NewBuffer(512)
+			fmtr.WriteMessage(buf, time.Time{}, logger.Info, "some message")
+
+			want := "INFO some message"
+			if test.want {
+				want = "\x1b[32mINFO\x1b[0m some message"
+			}
+			assert.Equal(t, want, string(buf.Bytes()))
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package logger
+
+import (
+	"io"
+
+	"golang.org/x/sys/windows"
+)
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(f.Fd()), &mode) == nil
+}
@@ -1,11 +1,16 @@
 package logger
 
-import "context"
+import (
+	"context"
+	"slices"
+
+	"go.opentelemetry.io/otel/trace"
+)
 
 type contextKey struct{}
 
 type ctxFields struct {
-	b []byte
+	fields []recordedField
 }
 
 // WithContext returns a new context carrying the given fields.
@@ -13,6 +18,9 @@ type ctxFields struct {
 // the new fields are appended after them. Any fields already
 // added to the logger are not attached to the context.
 //
+// The fields are evaluated once, and rendered by the logger they are
+// retrieved with, using its formatter, redactor and duplicate policy.
+//
 // If the logger discards output or no fields are given, ctx is returned
 // unchanged.
 func WithContext(ctx context.Context, log *Logger, fields ...Field) context.Context {
@@ -20,25 +28,19 @@ func WithContext(ctx context.Context, log *Logger, fields ...Field) context.Cont
 		return ctx
 	}
 
-	e := newEvent(log.fmtr)
-	defer putEvent(e)
-
-	if existing, _ := ctx.Value(contextKey{}).(*ctxFields); existing != nil {
-		e.buf.Write(existing.b)
-	}
+	recs := recordFields(fields)
 
-	for _, field := range fields {
-		field(e)
+	existing, _ := ctx.Value(contextKey{}).(*ctxFields)
+	if existing == nil {
+		existing = &ctxFields{}
 	}
+	f := &ctxFields{fields: slices.Concat(existing.fields, recs)}
 
-	b := make([]byte, e.buf.Len())
-	copy(b, e.buf.Bytes())
-
-	return context.WithValue(ctx, contextKey{}, &ctxFields{b: b})
+	return context.WithValue(ctx, contextKey{}, f)
 }
 
 // FromContext returns a new Logger extended with any fields attached to ctx
-// via WithContext. The context fields appear after the logger's own pre-rendered
+// via WithContext. The context fields appear after the logger's own
 // fields (set via With) and before per-call fields.
 //
 // If the logger discards output or ctx carries no fields, the receiver is
@@ -49,20 +51,85 @@ func (l *Logger) FromContext(ctx context.Context) *Logger {
 	}
 
 	fields, ok := ctx.Value(contextKey{}).(*ctxFields)
-	if !ok || len(fields.b) == 0 {
+	if !ok || len(fields.fields) == 0 {
 		return l
 	}
 
-	b := make([]byte, len(l.ctx)+len(fields.b))
-	copy(b, l.ctx)
-	copy(b[len(l.ctx):], fields.b)
-
-	return &Logger{
-		w:         l.w,
-		isDiscard: l.isDiscard,
-		fmtr:      l.fmtr,
-		timeFn:    l.timeFn,
-		lvl:       l.lvl,
-		ctx:       b,
+	log := l.withCtx(l.render(l.fmtr, l.ctx, l.ctxKeys, fields.fields))
+	log.fields = slices.Concat(l.fields, fields.fields)
+	if l.hook != nil {
+		log.hookCtx, log.hookKeys = l.render(l.hook.Formatter(), l.hookCtx, l.hookKeys, fields.fields)
+	}
+	return log
+}
+
+// TraceKeys are the keys of the trace fields added by Ctx.
+// Fields with an empty key are not added.
+type TraceKeys struct {
+	TraceID    string
+	SpanID     string
+	TraceFlags string
+}
+
+// DefaultTraceKeys are the trace keys used by a new Logger.
+var DefaultTraceKeys = TraceKeys{
+	TraceID:    "trace_id",
+	SpanID:     "span_id",
+	TraceFlags: "trace_flags",
+}
+
+// WithTraceKeys returns a new Logger using the given keys for the trace
+// fields added by Ctx. Passing the zero TraceKeys disables the trace fields.
+func (l *Logger) WithTraceKeys(keys TraceKeys) *Logger {
+	log := l.withCtx(l.ctx, l.ctxKeys)
+	log.traceKeys = keys
+	return log
+}
+
+// Ctx returns a new Logger extended with any fields attached to ctx via
+// WithContext, as FromContext does, and the trace ID, span ID and trace
+// flags of the OpenTelemetry span in ctx.
+//
+// The context is passed to the hook of the logger, if any, and the trace
+// fields are rendered for it.
+//
+// If the logger discards output, or ctx carries neither fields nor a valid
+// span context, the receiver is returned unchanged.
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	log := l.FromContext(ctx)
+	if log.isDiscard {
+		return log
+	}
+	if log.hook != nil {
+		if log == l {
+			log = l.withCtx(l.ctx, l.ctxKeys)
+		}
+		log.goCtx = ctx
+	}
+	if log.traceKeys == (TraceKeys{}) {
+		return log
+	}
+
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return log
+	}
+
+	var fields []recordedField
+	if log.traceKeys.TraceID != "" {
+		fields = append(fields, recordedField{key: log.traceKeys.TraceID, val: sc.TraceID().String()})
+	}
+	if log.traceKeys.SpanID != "" {
+		fields = append(fields, recordedField{key: log.traceKeys.SpanID, val: sc.SpanID().String()})
+	}
+	if log.traceKeys.TraceFlags != "" {
+		fields = append(fields, recordedField{key: log.traceKeys.TraceFlags, val: sc.TraceFlags().String()})
+	}
+
+	traced := log.withCtx(log.render(log.fmtr, log.ctx, log.ctxKeys, fields))
+	traced.fields = slices.Concat(log.fields, fields)
+	if log.hook != nil {
+		traced.hookCtx, traced.hookKeys = log.render(log.hook.Formatter(), log.hookCtx, log.hookKeys, fields)
 	}
+	return traced
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/hamba/logger/v2"
 	"github.com/hamba/logger/v2/ctx"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestLogger_WithContextAttachesFields_Logfmt(t *testing.T) {
@@ -93,3 +94,76 @@ func TestLogger_FromContextWithDiscardReturnsSameLogger(t *testing.T) {
 
 	assert.Same(t, discardLog, got)
 }
+
+func TestLogger_CtxAddsTraceFields(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info).With(ctx.Str("svc", "api"))
+
+	goCtx := logger.WithContext(context.Background(), log, ctx.Str("req_id", "abc123"))
+	goCtx = trace.ContextWithSpanContext(goCtx, newSpanContext())
+	log.Ctx(goCtx).Info("handled", ctx.Int("status", 200))
+
+	want := "lvl=info msg=handled svc=api req_id=abc123 trace_id=01000000000000000000000000000000 span_id=0200000000000000 trace_flags=01 status=200\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestLogger_CtxWithTraceKeys(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info).WithTraceKeys(logger.TraceKeys{
+		TraceID: "tid",
+		SpanID:  "sid",
+	})
+
+	goCtx := trace.ContextWithSpanContext(context.Background(), newSpanContext())
+	log.Ctx(goCtx).Info("handled")
+
+	want := "lvl=info msg=handled tid=01000000000000000000000000000000 sid=0200000000000000\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestLogger_CtxWithoutTraceKeys(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info).WithTraceKeys(logger.TraceKeys{})
+
+	goCtx := logger.WithContext(context.Background(), log, ctx.Str("req_id", "abc123"))
+	goCtx = trace.ContextWithSpanContext(goCtx, newSpanContext())
+	log.Ctx(goCtx).Info("handled")
+
+	assert.Equal(t, "lvl=info msg=handled req_id=abc123\n", buf.String())
+}
+
+func TestLogger_CtxWithNoSpanOrFieldsReturnsSameLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+
+	got := log.Ctx(context.Background())
+
+	assert.Same(t, log, got)
+}
+
+func TestLogger_CtxWithDiscardReturnsSameLogger(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LogfmtFormat(), logger.Info)
+	goCtx := trace.ContextWithSpanContext(context.Background(), newSpanContext())
+
+	got := log.Ctx(goCtx)
+
+	assert.Same(t, log, got)
+}
+
+func newSpanContext() trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    [16]byte{1},
+		SpanID:     [8]byte{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+}
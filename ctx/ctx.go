@@ -26,6 +26,14 @@ func Strs(k string, s []string) logger.Field {
 	}
 }
 
+// Secret returns a secret string context field. The value is never
+// rendered in clear, see logger.Secret.
+func Secret(k, s string) logger.Field {
+	return func(e *logger.Event) {
+		e.AppendString(k, logger.Secret(s).String())
+	}
+}
+
 // Bytes returns a byte slice context field.
 func Bytes(k string, p []byte) logger.Field {
 	return func(e *logger.Event) {
@@ -153,7 +161,7 @@ func Stack(k string) logger.Field {
 		c := stack.Caller(3)
 		cs = cs.TrimBelow(c)
 
-		e.AppendString(k, fmt.Sprintf("%+v", cs))
+		e.AppendStack(k, fmt.Sprintf("%+v", cs))
 	}
 }
 
@@ -203,3 +211,23 @@ func TraceID(k string, span Span) logger.Field {
 		e.AppendString(k, span.SpanContext().TraceID().String())
 	}
 }
+
+// SpanID returns an open telemetry span ID context field.
+func SpanID(k string, span Span) logger.Field {
+	if !span.IsRecording() || !span.SpanContext().HasSpanID() {
+		return func(*logger.Event) {}
+	}
+	return func(e *logger.Event) {
+		e.AppendString(k, span.SpanContext().SpanID().String())
+	}
+}
+
+// TraceFlags returns an open telemetry trace flags context field.
+func TraceFlags(k string, span Span) logger.Field {
+	if !span.IsRecording() || !span.SpanContext().IsValid() {
+		return func(*logger.Event) {}
+	}
+	return func(e *logger.Event) {
+		e.AppendString(k, span.SpanContext().TraceFlags().String())
+	}
+}
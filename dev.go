@@ -0,0 +1,288 @@
+package logger
+
+import (
+	stdbytes "bytes"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hamba/logger/v2/internal/bytes"
+)
+
+// Markers delimiting the parts of an entry in the dev console format,
+// which is laid out when the entry ends. Control characters in values
+// are escaped, so the markers cannot be written by values.
+const (
+	devMsgMarker   = '\x1d'
+	devKeyMarker   = '\x1f'
+	devValMarker   = '\x1e'
+	devStackMarker = '\x1c'
+)
+
+// devInlineWidth is the maximum width of values written inline.
+const devInlineWidth = 80
+
+var devPool = &sync.Pool{
+	New: func() any {
+		return bytes.NewBuffer(512)
+	},
+}
+
+// WithMessageWidth sets the width of the message column of the dev
+// console formatter. It defaults to 40.
+func WithMessageWidth(width int) FormatOption {
+	return func(c *formatConfig) {
+		c.msgWidth = max(width, 0)
+	}
+}
+
+// WithRelativeTime makes the dev console formatter write timestamps as
+// the time elapsed since start.
+func WithRelativeTime(start time.Time) FormatOption {
+	return func(c *formatConfig) {
+		c.relStart = start
+	}
+}
+
+// StackAppender is implemented by formatters that write stack traces
+// from ctx.Stack differently from strings.
+type StackAppender interface {
+	AppendStack(buf *bytes.Buffer, s string)
+}
+
+type devConsole struct {
+	console
+}
+
+// DevConsoleFormat formats a log line in an aligned console format
+// intended for development.
+//
+// Levels and messages are written in fixed width columns, aligning the
+// fields after them. Multi-line messages, multi-line or long values and
+// stack traces from ctx.Stack are written on indented continuation lines.
+func DevConsoleFormat(opts ...FormatOption) Formatter {
+	return &devConsole{console: *ConsoleFormat(opts...).(*console)}
+}
+
+func (c *devConsole) WriteMessage(buf *bytes.Buffer, ts time.Time, lvl Level, msg string) {
+	if !ts.IsZero() {
+		c.withColor(c.palette.Time, buf, func() {
+			if c.cfg.relStart.IsZero() {
+				c.AppendTime(buf, ts)
+				return
+			}
+			appendElapsed(buf, ts.Sub(c.cfg.relStart))
+		})
+		buf.WriteByte(' ')
+	}
+
+	name := strings.ToUpper(lvl.String())
+	c.withColor(c.palette.Levels[lvl], buf, func() {
+		buf.WriteString(name)
+	})
+	for range 4 - len(name) {
+		buf.WriteByte(' ')
+	}
+	buf.WriteByte(' ')
+
+	buf.WriteByte(devMsgMarker)
+	appendLines(buf, msg)
+}
+
+// appendElapsed appends the elapsed seconds in a fixed width.
+func appendElapsed(buf *bytes.Buffer, d time.Duration) {
+	var b [32]byte
+	s := append(b[:0], '+')
+	if d < 0 {
+		s[0] = '-'
+		d = -d
+	}
+	s = appendFixed(s, d)
+	for range 10 - len(s) {
+		buf.WriteByte(' ')
+	}
+	buf.Write(s)
+}
+
+// appendFixed appends the duration in seconds with 3 decimals.
+func appendFixed(b []byte, d time.Duration) []byte {
+	ms := d.Milliseconds()
+	b = appendUint(b, uint64(ms/1000))
+	b = append(b, '.', byte('0'+ms/100%10), byte('0'+ms/10%10), byte('0'+ms%10), 's')
+	return b
+}
+
+func appendUint(b []byte, u uint64) []byte {
+	if u >= 10 {
+		b = appendUint(b, u/10)
+	}
+	return append(b, byte('0'+u%10))
+}
+
+// appendLines appends a string, escaped as in the console format except
+// for line breaks.
+func appendLines(buf *bytes.Buffer, s string) {
+	for {
+		line, rest, found := strings.Cut(s, "\n")
+		appendString(buf, line, false)
+		if !found {
+			return
+		}
+		buf.WriteByte('\n')
+		s = rest
+	}
+}
+
+func (c *devConsole) AppendEndMarker(buf *bytes.Buffer) {
+	c.layout(buf)
+}
+
+func (c *devConsole) AppendKey(buf *bytes.Buffer, key string) {
+	buf.WriteByte(devKeyMarker)
+	appendLogfmtKey(buf, key)
+	buf.WriteByte(devValMarker)
+}
+
+func (c *devConsole) AppendString(buf *bytes.Buffer, s string) {
+	appendLines(buf, s)
+}
+
+// AppendStack appends the stack trace marked, so it is laid out with a
+// frame per line.
+func (c *devConsole) AppendStack(buf *bytes.Buffer, s string) {
+	buf.WriteByte(devStackMarker)
+	appendString(buf, s, false)
+}
+
+func (c *devConsole) AppendInterface(buf *bytes.Buffer, v any) {
+	if v == nil {
+		return
+	}
+
+	c.console.AppendInterface(buf, v)
+}
+
+// layout lays out the entry in buf in columns and continuation lines.
+func (c *devConsole) layout(buf *bytes.Buffer) {
+	b := buf.Bytes()
+	i := stdbytes.IndexByte(b, devMsgMarker)
+	if i < 0 {
+		return
+	}
+
+	out := devPool.Get().(*bytes.Buffer)
+	defer devPool.Put(out)
+	out.Reset()
+
+	header := b[:i]
+	indent := visibleWidth(header)
+	rest := b[i+1:]
+	msg := rest
+	if j := stdbytes.IndexByte(rest, devKeyMarker); j >= 0 {
+		msg, rest = rest[:j], rest[j:]
+	} else {
+		rest = nil
+	}
+	msgLines := stdbytes.Split(msg, []byte{'\n'})
+
+	out.Write(header)
+	out.Write(msgLines[0])
+
+	var blocks [][]byte
+	pad := c.cfg.msgWidth - utf8.RuneCount(msgLines[0])
+	for len(rest) > 0 {
+		field := rest[1:]
+		if j := stdbytes.IndexByte(field, devKeyMarker); j >= 0 {
+			field, rest = field[:j], field[j:]
+		} else {
+			rest = nil
+		}
+
+		_, val, _ := stdbytes.Cut(field, []byte{devValMarker})
+		if isBlock(val) {
+			blocks = append(blocks, field)
+			continue
+		}
+
+		for ; pad > 0; pad-- {
+			out.WriteByte(' ')
+		}
+		out.WriteByte(' ')
+		c.appendField(out, field)
+	}
+
+	for _, line := range msgLines[1:] {
+		out.WriteByte('\n')
+		writeIndent(out, indent)
+		out.Write(line)
+	}
+
+	for _, field := range blocks {
+		out.WriteByte('\n')
+		writeIndent(out, indent)
+
+		key, val, _ := stdbytes.Cut(field, []byte{devValMarker})
+		if !isStack(val) && stdbytes.IndexByte(val, '\n') < 0 {
+			c.appendField(out, field)
+			continue
+		}
+
+		c.appendField(out, key)
+		lines := stdbytes.Split(val, []byte{'\n'})
+		if isStack(val) {
+			lines = stdbytes.Fields(stdbytes.Trim(val[1:], "[]"))
+		}
+		for _, line := range lines {
+			out.WriteByte('\n')
+			writeIndent(out, indent+2)
+			out.Write(line)
+		}
+	}
+
+	buf.Reset()
+	buf.Write(out.Bytes())
+}
+
+// appendField appends a field in the form key=value, coloring the key.
+func (c *devConsole) appendField(out *bytes.Buffer, field []byte) {
+	key, val, _ := stdbytes.Cut(field, []byte{devValMarker})
+	c.withColor(c.palette.keyColor(string(key)), out, func() {
+		out.Write(key)
+		out.WriteByte('=')
+	})
+	out.Write(val)
+}
+
+// isBlock reports whether a value is written on continuation lines.
+func isBlock(val []byte) bool {
+	return stdbytes.IndexByte(val, '\n') >= 0 || utf8.RuneCount(val) > devInlineWidth || isStack(val)
+}
+
+// isStack reports whether a value is a stack trace marked by AppendStack.
+func isStack(val []byte) bool {
+	return len(val) > 0 && val[0] == devStackMarker
+}
+
+func writeIndent(out *bytes.Buffer, n int) {
+	for range n {
+		out.WriteByte(' ')
+	}
+}
+
+// visibleWidth returns the width of b, ignoring color escape codes.
+func visibleWidth(b []byte) int {
+	n := 0
+	for i := 0; i < len(b); {
+		if b[i] == '\x1b' {
+			if j := stdbytes.IndexByte(b[i:], 'm'); j >= 0 {
+				i += j + 1
+				continue
+			}
+		}
+		_, size := utf8.DecodeRune(b[i:])
+		i += size
+		n++
+	}
+	return n
+}
@@ -0,0 +1,180 @@
+package logger_test
+
+import (
+	stdbytes "bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/internal/bytes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevConsoleFormat(t *testing.T) {
+	fmtr := logger.DevConsoleFormat(logger.WithMessageWidth(16))
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendBeginMarker(buf)
+	fmtr.WriteMessage(buf, time.Unix(123, 0).UTC(), logger.Error, "some message")
+	fmtr.AppendKey(buf, "error")
+	fmtr.AppendString(buf, "some error")
+	fmtr.AppendKey(buf, "count")
+	fmtr.AppendInt(buf, 2)
+	fmtr.AppendEndMarker(buf)
+	fmtr.AppendLineBreak(buf)
+
+	want := "\x1b[34m12:02AM\x1b[0m \x1b[31mEROR\x1b[0m some message     \x1b[31merror=\x1b[0msome error \x1b[36mcount=\x1b[0m2\n"
+	assert.Equal(t, want, string(buf.Bytes()))
+}
+
+func TestDevConsoleFormat_Layout(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    string
+		fields []logger.Field
+		want   string
+	}{
+		{
+			name:   "aligns fields",
+			msg:    "short",
+			fields: []logger.Field{ctx.Str("key", "value")},
+			want:   "INFO short        key=value\n",
+		},
+		{
+			name:   "long message",
+			msg:    "a longer message",
+			fields: []logger.Field{ctx.Str("key", "value")},
+			want:   "INFO a longer message key=value\n",
+		},
+		{
+			name: "no fields",
+			msg:  "short",
+			want: "INFO short\n",
+		},
+		{
+			name:   "multi-line message",
+			msg:    "first\nsecond",
+			fields: []logger.Field{ctx.Int("key", 1)},
+			want:   "INFO first        key=1\n     second\n",
+		},
+		{
+			name:   "multi-line value",
+			msg:    "msg",
+			fields: []logger.Field{ctx.Str("body", "a\n\tb"), ctx.Int("key", 1)},
+			want:   "INFO msg          key=1\n     body=\n       a\n       \\tb\n",
+		},
+		{
+			name:   "long value",
+			msg:    "msg",
+			fields: []logger.Field{ctx.Str("body", strings.Repeat("a", 81))},
+			want:   "INFO msg\n     body=" + strings.Repeat("a", 81) + "\n",
+		},
+		{
+			name:   "escaped value",
+			msg:    "msg",
+			fields: []logger.Field{ctx.Str("key", "a\x1fb\x1dc")},
+			want:   "INFO msg          key=a\\u001fb\\u001dc\n",
+		},
+		{
+			name:   "stack",
+			msg:    "msg",
+			fields: []logger.Field{stackField("stack", "[a/b.go:1 c/d.go:2]")},
+			want:   "INFO msg\n     stack=\n       a/b.go:1\n       c/d.go:2\n",
+		},
+		{
+			name:   "bracketed string",
+			msg:    "msg",
+			fields: []logger.Field{ctx.Str("note", "[see main.go:12 for details]")},
+			want:   "INFO msg          note=[see main.go:12 for details]\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf stdbytes.Buffer
+			log := logger.New(&buf, logger.DevConsoleFormat(logger.WithColor(false), logger.WithMessageWidth(12)), logger.Info)
+
+			log.Info(test.msg, test.fields...)
+
+			assert.Equal(t, test.want, buf.String())
+		})
+	}
+}
+
+func TestDevConsoleFormat_CtxStack(t *testing.T) {
+	var buf stdbytes.Buffer
+	log := logger.New(&buf, logger.DevConsoleFormat(logger.WithColor(false), logger.WithMessageWidth(4)), logger.Info)
+	log = log.With(ctx.Stack("stack"))
+
+	log.Info("msg")
+
+	assert.Regexp(t, `^INFO msg\n     stack=\n       \S+/dev_test\.go:\d+\n$`, buf.String())
+}
+
+func stackField(k, s string) logger.Field {
+	return func(e *logger.Event) {
+		e.AppendStack(k, s)
+	}
+}
+
+func TestDevConsoleFormat_WithContext(t *testing.T) {
+	var buf stdbytes.Buffer
+	log := logger.New(&buf, logger.DevConsoleFormat(logger.WithColor(false), logger.WithMessageWidth(4)), logger.Info)
+	log = log.With(ctx.Str("svc", "api"), ctx.Str("trace", "a\nb"))
+
+	log.Warn("msg", ctx.Int("n", 1))
+
+	assert.Equal(t, "WARN msg  svc=api n=1\n     trace=\n       a\n       b\n", buf.String())
+}
+
+func TestDevConsoleFormat_WithRelativeTime(t *testing.T) {
+	start := time.Unix(100, 0)
+	fmtr := logger.DevConsoleFormat(logger.WithColor(false), logger.WithRelativeTime(start), logger.WithMessageWidth(0))
+
+	tests := []struct {
+		name string
+		ts   time.Time
+		want string
+	}{
+		{
+			name: "seconds",
+			ts:   start.Add(1234567 * time.Microsecond),
+			want: "   +1.234s INFO msg k=v\n",
+		},
+		{
+			name: "minutes",
+			ts:   start.Add(2 * time.Minute),
+			want: " +120.000s INFO msg k=v\n",
+		},
+		{
+			name: "before start",
+			ts:   start.Add(-50 * time.Millisecond),
+			want: "   -0.050s INFO msg k=v\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(512)
+			fmtr.AppendBeginMarker(buf)
+			fmtr.WriteMessage(buf, test.ts, logger.Info, "msg")
+			fmtr.AppendKey(buf, "k")
+			fmtr.AppendString(buf, "v")
+			fmtr.AppendEndMarker(buf)
+			fmtr.AppendLineBreak(buf)
+
+			assert.Equal(t, test.want, string(buf.Bytes()))
+		})
+	}
+}
+
+func TestDevConsoleFormat_ContinuationIndentIgnoresColors(t *testing.T) {
+	var buf stdbytes.Buffer
+	log := logger.New(&buf, logger.DevConsoleFormat(logger.WithColor(true), logger.WithMessageWidth(0)), logger.Info)
+
+	log.Info("first\nsecond")
+
+	assert.Equal(t, "\x1b[32mINFO\x1b[0m first\n     second\n", buf.String())
+}
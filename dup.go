@@ -0,0 +1,156 @@
+package logger
+
+import "strconv"
+
+// DuplicatePolicy is the way fields with duplicate keys are handled.
+type DuplicatePolicy int
+
+// Duplicate key policies.
+const (
+	// DuplicateKeepAll writes all fields, even if their keys are duplicated.
+	DuplicateKeepAll DuplicatePolicy = iota
+	// DuplicateLastWins writes only the last field with a key.
+	DuplicateLastWins
+	// DuplicateFirstWins writes only the first field with a key.
+	DuplicateFirstWins
+	// DuplicateRename writes all fields, renaming duplicate keys with
+	// a numbered suffix, e.g. "user_1".
+	DuplicateRename
+)
+
+// WithDuplicatePolicy returns a new Logger handling duplicate keys
+// across With, WithContext and per-call fields with the given policy.
+//
+// Fields already added to the logger are rendered again with the policy.
+func (l *Logger) WithDuplicatePolicy(p DuplicatePolicy) *Logger {
+	log := l.withCtx(nil, nil)
+	log.dups = p
+	log.rerender()
+	return log
+}
+
+// fieldSpan is the position of a rendered field in a buffer.
+type fieldSpan struct {
+	key   string
+	start int
+	val   int
+	end   int
+}
+
+// appendKey appends the key of a field, returning false if the field
+// is dropped by the duplicate policy.
+func (e *Event) appendKey(k string) bool {
+	if e.dups == DuplicateKeepAll {
+		e.fmtr.AppendKey(e.buf, k)
+		return true
+	}
+
+	k, ok := e.dedupKey(k)
+	if !ok {
+		return false
+	}
+
+	e.field = fieldSpan{key: k, start: e.buf.Len()}
+	e.fmtr.AppendKey(e.buf, k)
+	e.field.val = e.buf.Len()
+	return true
+}
+
+// endField ends the field started with appendKey.
+func (e *Event) endField() {
+	if e.dups == DuplicateKeepAll {
+		return
+	}
+
+	e.field.end = e.buf.Len()
+	e.keys = append(e.keys, e.field)
+}
+
+// writeCtx writes pre-rendered fields, applying the duplicate policy
+// against the fields already written.
+func (e *Event) writeCtx(b []byte, keys []fieldSpan) {
+	if e.dups == DuplicateKeepAll {
+		e.buf.Write(b)
+		return
+	}
+
+	if len(e.keys) == 0 {
+		off := e.buf.Len()
+		e.buf.Write(b)
+		for _, f := range keys {
+			f.start += off
+			f.val += off
+			f.end += off
+			e.keys = append(e.keys, f)
+		}
+		return
+	}
+
+	for _, f := range keys {
+		k, ok := e.dedupKey(f.key)
+		if !ok {
+			continue
+		}
+
+		start := e.buf.Len()
+		if k == f.key {
+			e.buf.Write(b[f.start:f.val])
+		} else {
+			e.fmtr.AppendKey(e.buf, k)
+		}
+		val := e.buf.Len()
+		e.buf.Write(b[f.val:f.end])
+
+		e.keys = append(e.keys, fieldSpan{key: k, start: start, val: val, end: e.buf.Len()})
+	}
+}
+
+// dedupKey returns the key to write a field with, or false if the field
+// should be dropped.
+func (e *Event) dedupKey(k string) (string, bool) {
+	i := e.findKey(k)
+	if i < 0 {
+		return k, true
+	}
+
+	switch e.dups {
+	case DuplicateFirstWins:
+		return "", false
+	case DuplicateLastWins:
+		e.removeField(i)
+		return k, true
+	default:
+		for n := 1; ; n++ {
+			name := k + "_" + strconv.Itoa(n)
+			if e.findKey(name) < 0 {
+				return name, true
+			}
+		}
+	}
+}
+
+func (e *Event) findKey(k string) int {
+	for i, f := range e.keys {
+		if f.key == k {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeField removes a written field, moving the fields after it.
+func (e *Event) removeField(i int) {
+	f := e.keys[i]
+	size := f.end - f.start
+
+	b := e.buf.Bytes()
+	n := copy(b[f.start:], b[f.end:])
+	e.buf.Truncate(f.start + n)
+
+	e.keys = append(e.keys[:i], e.keys[i+1:]...)
+	for j := i; j < len(e.keys); j++ {
+		e.keys[j].start -= size
+		e.keys[j].val -= size
+		e.keys[j].end -= size
+	}
+}
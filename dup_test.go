@@ -0,0 +1,113 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_WithDuplicatePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy logger.DuplicatePolicy
+		fmtr   logger.Formatter
+		want   string
+	}{
+		{
+			name:   "keep all",
+			policy: logger.DuplicateKeepAll,
+			fmtr:   logger.LogfmtFormat(),
+			want:   "lvl=info msg=x user=a svc=api user=b req=1 user=c req=2\n",
+		},
+		{
+			name:   "last wins",
+			policy: logger.DuplicateLastWins,
+			fmtr:   logger.LogfmtFormat(),
+			want:   "lvl=info msg=x svc=api user=c req=2\n",
+		},
+		{
+			name:   "first wins",
+			policy: logger.DuplicateFirstWins,
+			fmtr:   logger.LogfmtFormat(),
+			want:   "lvl=info msg=x user=a svc=api req=1\n",
+		},
+		{
+			name:   "rename",
+			policy: logger.DuplicateRename,
+			fmtr:   logger.LogfmtFormat(),
+			want:   "lvl=info msg=x user=a svc=api user_1=b req=1 user_2=c req_1=2\n",
+		},
+		{
+			name:   "last wins json",
+			policy: logger.DuplicateLastWins,
+			fmtr:   logger.JSONFormat(),
+			want:   `{"lvl":"info","msg":"x","svc":"api","user":"c","req":2}` + "\n",
+		},
+		{
+			name:   "rename json",
+			policy: logger.DuplicateRename,
+			fmtr:   logger.JSONFormat(),
+			want:   `{"lvl":"info","msg":"x","user":"a","svc":"api","user_1":"b","req":1,"user_2":"c","req_1":2}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, test.fmtr, logger.Info).
+				WithDuplicatePolicy(test.policy).
+				With(ctx.Str("user", "a"), ctx.Str("svc", "api"))
+
+			c := logger.WithContext(context.Background(), log, ctx.Str("user", "b"), ctx.Int("req", 1))
+			log.FromContext(c).Info("x", ctx.Str("user", "c"), ctx.Int("req", 2))
+
+			assert.Equal(t, test.want, buf.String())
+		})
+	}
+}
+
+func TestLogger_WithDuplicatePolicyWithinWith(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info).
+		WithDuplicatePolicy(logger.DuplicateLastWins).
+		With(ctx.Str("user", "a"), ctx.Str("user", "b")).
+		With(ctx.Strs("user", []string{"c", "d"}))
+
+	log.Info("x")
+	log.Info("y", ctx.Str("user", "e"))
+
+	assert.Equal(t, "lvl=info msg=x user=c,d\nlvl=info msg=y user=e\n", buf.String())
+}
+
+func TestLogger_WithDuplicatePolicyAfterWith(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info).
+		With(ctx.Str("user", "a"), ctx.Str("svc", "api"), ctx.Str("svc", "web")).
+		WithDuplicatePolicy(logger.DuplicateLastWins)
+
+	log.Info("dup", ctx.Str("user", "b"))
+
+	assert.Equal(t, "lvl=info msg=dup svc=web user=b\n", buf.String())
+}
+
+func TestLogger_WithDuplicatePolicyAndRedactor(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info).
+		WithRedactor(logger.NewRedactor(logger.RedactKeys("password"))).
+		WithDuplicatePolicy(logger.DuplicateFirstWins).
+		With(ctx.Str("password", "a"))
+
+	log.Info("x", ctx.Str("password", "b"), ctx.Int("n", 1))
+
+	assert.Equal(t, "lvl=info msg=x password=[REDACTED] n=1\n", buf.String())
+}
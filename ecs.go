@@ -0,0 +1,120 @@
+package logger
+
+import (
+	stdbytes "bytes"
+	"strings"
+	"time"
+
+	"github.com/hamba/logger/v2/internal/bytes"
+)
+
+// ECSVersion is the Elastic Common Schema version written by the ecs formatter.
+const ECSVersion = "8.11.0"
+
+const (
+	ecsFileKey  = "log.origin.file.name"
+	ecsErrorKey = "error.message"
+)
+
+// ecsHeaderEnd ends the fields written by the ecs formatter before the
+// context, and ecsErrorField starts an error field.
+var (
+	ecsHeaderEnd  = []byte(`,"ecs":{"version":"` + ECSVersion + `"}`)
+	ecsErrorField = []byte(`,"` + ecsErrorKey + `":`)
+)
+
+type ecs struct {
+	json
+}
+
+// ECSFormat formats a log line in the Elastic Common Schema (ECS) format.
+//
+// Times are written in RFC3339 with nanoseconds and dotted keys are nested
+// into objects. The well known keys are mapped onto their ECS fields:
+// "caller" onto log.origin.file.name and log.origin.file.line, "error" and
+// "err" onto error.message and "stack" onto error.stack_trace. When both
+// "error" and "err" are logged, the first is mapped and the other is kept
+// as is.
+//
+// ECS requires a timestamp, which is only written when the logger has
+// timestamps enabled.
+func ECSFormat() Formatter {
+	return &ecs{}
+}
+
+func (e *ecs) WriteMessage(buf *bytes.Buffer, ts time.Time, lvl Level, msg string) {
+	if !ts.IsZero() {
+		buf.WriteString(`"@timestamp":`)
+		e.AppendTime(buf, ts)
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"log":{"level":"`)
+	buf.WriteString(ecsLevel(lvl))
+	buf.WriteString(`"},"message":`)
+	appendString(buf, msg, true)
+	buf.Write(ecsHeaderEnd)
+}
+
+func (e *ecs) AppendEndMarker(buf *bytes.Buffer) {
+	buf.WriteByte('}')
+
+	// Only entries with dotted keys are rewritten, the fields written
+	// before the context being nested already.
+	b := buf.Bytes()
+	if i := stdbytes.Index(b, ecsHeaderEnd); i >= 0 && hasDottedKey(b[i+len(ecsHeaderEnd):]) {
+		nestJSON(buf, splitDots)
+	}
+}
+
+func (e *ecs) AppendKey(buf *bytes.Buffer, key string) {
+	switch key {
+	case "caller":
+		key = ecsFileKey
+	case "error", "err":
+		if !stdbytes.Contains(buf.Bytes(), ecsErrorField) {
+			key = ecsErrorKey
+		}
+	case "stack":
+		key = "error.stack_trace"
+	}
+
+	e.json.AppendKey(buf, key)
+}
+
+func (e *ecs) AppendString(buf *bytes.Buffer, s string) {
+	if hasSuffix(buf.Bytes(), `"`+ecsFileKey+`":`) {
+		if i := strings.LastIndexByte(s, ':'); i > 0 && isDigits(s[i+1:]) {
+			appendString(buf, s[:i], true)
+			buf.WriteString(`,"log.origin.file.line":`)
+			buf.WriteString(s[i+1:])
+			return
+		}
+	}
+
+	appendString(buf, s, true)
+}
+
+func (e *ecs) AppendTime(buf *bytes.Buffer, t time.Time) {
+	buf.WriteByte('"')
+	buf.AppendTime(t, timeFormatNano)
+	buf.WriteByte('"')
+}
+
+func ecsLevel(lvl Level) string {
+	switch lvl {
+	case Trace:
+		return "trace"
+	case Debug:
+		return "debug"
+	case Info:
+		return "info"
+	case Warn:
+		return "warn"
+	case Error:
+		return "error"
+	case Crit:
+		return "crit"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,188 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	ibytes "github.com/hamba/logger/v2/internal/bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECSFormat(t *testing.T) {
+	fmtr := logger.ECSFormat()
+
+	buf := ibytes.NewBuffer(512)
+	fmtr.AppendBeginMarker(buf)
+	fmtr.WriteMessage(buf, time.Unix(123, 456).UTC(), logger.Error, "some message")
+	fmtr.AppendKey(buf, "error")
+	fmtr.AppendString(buf, "some error")
+	fmtr.AppendEndMarker(buf)
+	fmtr.AppendLineBreak(buf)
+
+	want := `{"@timestamp":"1970-01-01T00:02:03.000000456Z","log":{"level":"error"},"message":"some message","ecs":{"version":"8.11.0"},"error":{"message":"some error"}}` + "\n"
+	assert.Equal(t, want, string(buf.Bytes()))
+}
+
+func TestECSFormat_NestsDottedKeys(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.ECSFormat(), logger.Info).With(ctx.Str("service.name", "api"))
+
+	log.Info("some message",
+		ctx.Str("http.request.method", "GET"),
+		ctx.Int("http.response.status_code", 200),
+		ctx.Strs("tags", []string{"a", "b"}),
+		ctx.Str("service.version", "1.0.0"),
+		ctx.Str("url.path", "/foo"),
+		ctx.Str("url.path.raw", "/foo?bar"),
+	)
+
+	want := `{"log":{"level":"info"},"message":"some message","ecs":{"version":"8.11.0"},"service":{"name":"api","version":"1.0.0"},"http":{"request":{"method":"GET"},"response":{"status_code":200}},"tags":["a","b"],"url":{"path":"/foo","path.raw":"/foo?bar"}}` + "\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestECSFormat_ValueConflictsWithNestedKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []logger.Field
+		want   string
+	}{
+		{
+			name:   "value after nested key",
+			fields: []logger.Field{ctx.Str("url.path", "/foo"), ctx.Str("url", "/foo?bar")},
+			want:   `"url.path":"/foo","url":"/foo?bar"`,
+		},
+		{
+			name:   "value before nested key",
+			fields: []logger.Field{ctx.Str("url", "/foo?bar"), ctx.Str("url.path", "/foo")},
+			want:   `"url":"/foo?bar","url.path":"/foo"`,
+		},
+		{
+			name: "deep value after nested keys",
+			fields: []logger.Field{
+				ctx.Str("a.b.c", "1"), ctx.Str("a.b.d.e", "2"), ctx.Str("a.f", "3"), ctx.Str("a.b", "4"),
+			},
+			want: `"a":{"b.c":"1","b.d.e":"2","f":"3","b":"4"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.ECSFormat(), logger.Info)
+
+			log.Info("msg", test.fields...)
+
+			want := `{"log":{"level":"info"},"message":"msg","ecs":{"version":"8.11.0"},` + test.want + "}\n"
+			assert.Equal(t, want, buf.String())
+			assert.NotContains(t, duplicateJSONKeys(t, buf.Bytes()), true)
+		})
+	}
+}
+
+// duplicateJSONKeys reports, for each object in the JSON document, whether
+// it has duplicate keys.
+func duplicateJSONKeys(t *testing.T, b []byte) []bool {
+	t.Helper()
+
+	var dups []bool
+	var walk func(dec *json.Decoder)
+	walk = func(dec *json.Decoder) {
+		tok, err := dec.Token()
+		require.NoError(t, err)
+
+		switch tok {
+		case json.Delim('{'):
+			seen := map[string]bool{}
+			dup := false
+			for dec.More() {
+				key, err := dec.Token()
+				require.NoError(t, err)
+				dup = dup || seen[key.(string)]
+				seen[key.(string)] = true
+				walk(dec)
+			}
+			_, err = dec.Token()
+			require.NoError(t, err)
+			dups = append(dups, dup)
+		case json.Delim('['):
+			for dec.More() {
+				walk(dec)
+			}
+			_, err = dec.Token()
+			require.NoError(t, err)
+		}
+	}
+	walk(json.NewDecoder(bytes.NewReader(b)))
+	return dups
+}
+
+func TestECSFormat_MapsKnownKeys(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.ECSFormat(), logger.Info)
+
+	log.Error("some message",
+		ctx.Err(errors.New("test error")),
+		ctx.Str("stack", "[main.go:12]"),
+		ctx.Str("caller", "/src/main.go:42"),
+	)
+
+	want := `{"log":{"level":"error","origin":{"file":{"name":"/src/main.go","line":42}}},"message":"some message","ecs":{"version":"8.11.0"},"error":{"message":"test error","stack_trace":"[main.go:12]"}}` + "\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestECSFormat_WithoutDottedKeys(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.ECSFormat(), logger.Info).With(ctx.Str("svc", "api"))
+
+	log.Info("some message", ctx.Str("path", "a.b"), ctx.Strs("tags", []string{"a,", "b.c"}), ctx.Int("n", 1))
+
+	want := `{"log":{"level":"info"},"message":"some message","ecs":{"version":"8.11.0"},"svc":"api","path":"a.b","tags":["a,","b.c"],"n":1}` + "\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestECSFormat_ErrorAliases(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []logger.Field
+		want   string
+	}{
+		{
+			name:   "error first",
+			fields: []logger.Field{ctx.Str("error", "a"), ctx.Str("err", "b")},
+			want:   `"error":{"message":"a"},"err":"b"`,
+		},
+		{
+			name:   "err first",
+			fields: []logger.Field{ctx.Str("err", "a"), ctx.Str("error", "b")},
+			want:   `"error.message":"a","error":"b"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.ECSFormat(), logger.Info)
+
+			log.Info("msg", test.fields...)
+
+			want := `{"log":{"level":"info"},"message":"msg","ecs":{"version":"8.11.0"},` + test.want + "}\n"
+			assert.Equal(t, want, buf.String())
+			assert.NotContains(t, duplicateJSONKeys(t, buf.Bytes()), true)
+		})
+	}
+}
+
+func TestECSFormat_Time(t *testing.T) {
+	fmtr := logger.ECSFormat()
+
+	buf := ibytes.NewBuffer(512)
+	fmtr.AppendTime(buf, time.Unix(1541573670, 0).UTC())
+
+	assert.Equal(t, `"2018-11-07T06:54:30.000000000Z"`, string(buf.Bytes()))
+}
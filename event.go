@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -17,43 +19,229 @@ var eventPool = &sync.Pool{
 
 // Event is a log event.
 type Event struct {
-	fmtr Formatter
-	buf  *bytes.Buffer
+	fmtr   Formatter
+	redact *Redactor
+	dups   DuplicatePolicy
+	buf    *bytes.Buffer
+
+	// Fields tracked for the duplicate policy.
+	keys  []fieldSpan
+	field fieldSpan
+
+	// Fields recorded instead of written, if recording.
+	record bool
+	recs   []recordedField
+}
+
+// recordedField is a field with its value evaluated, so it can be written
+// again without calling the Field. The value is one of string, []string,
+// []byte, bool, int64, []int, uint64, float64, time.Time, time.Duration,
+// stackTrace or nil.
+type recordedField struct {
+	key string
+	val any
 }
 
-func newEvent(fmtr Formatter) *Event {
+// stackTrace is a recorded stack trace.
+type stackTrace string
+
+func newEvent(fmtr Formatter, redact *Redactor, dups DuplicatePolicy) *Event {
 	e := eventPool.Get().(*Event)
 	e.fmtr = fmtr
+	e.redact = redact
+	e.dups = dups
 	e.buf.Reset()
+	e.keys = e.keys[:0]
+	e.record = false
 	return e
 }
 
+// recordFields evaluates the fields once, returning their values.
+//
+// It must be called directly by the Logger method given the fields, as
+// the caller and stack fields skip a fixed number of frames.
+func recordFields(fields []Field) []recordedField {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	e := newEvent(nil, nil, DuplicateKeepAll)
+	defer putEvent(e)
+
+	e.record = true
+	for _, field := range fields {
+		field(e)
+	}
+	return slices.Clone(e.recs)
+}
+
+// recordField records a field instead of writing it.
+func (e *Event) recordField(k string, v any) {
+	e.recs = append(e.recs, recordedField{key: k, val: v})
+}
+
+// replay writes the recorded fields.
+func (e *Event) replay(fields []recordedField) {
+	for _, f := range fields {
+		switch v := f.val.(type) {
+		case string:
+			e.AppendString(f.key, v)
+		case []string:
+			e.AppendStrings(f.key, v)
+		case []byte:
+			e.AppendBytes(f.key, v)
+		case bool:
+			e.AppendBool(f.key, v)
+		case int64:
+			e.AppendInt(f.key, v)
+		case []int:
+			e.AppendInts(f.key, v)
+		case uint64:
+			e.AppendUint(f.key, v)
+		case float64:
+			e.AppendFloat(f.key, v)
+		case time.Time:
+			e.AppendTime(f.key, v)
+		case time.Duration:
+			e.AppendDuration(f.key, v)
+		case stackTrace:
+			e.AppendStack(f.key, string(v))
+		default:
+			e.AppendInterface(f.key, nil)
+		}
+	}
+}
+
+// appendMasked appends the masked value of a redacted key.
+func (e *Event) appendMasked(k string, v any) {
+	if !e.appendKey(k) {
+		return
+	}
+	e.fmtr.AppendString(e.buf, e.redact.value(v))
+	e.endField()
+}
+
+func (e *Event) appendString(s string) {
+	if e.redact != nil && len(e.redact.patterns) > 0 {
+		s = e.redact.String(s)
+	}
+	e.fmtr.AppendString(e.buf, s)
+}
+
+// rendered returns a copy of the rendered fields.
+func (e *Event) rendered() ([]byte, []fieldSpan) {
+	b := make([]byte, e.buf.Len())
+	copy(b, e.buf.Bytes())
+
+	var keys []fieldSpan
+	if len(e.keys) > 0 {
+		keys = make([]fieldSpan, len(e.keys))
+		copy(keys, e.keys)
+	}
+	return b, keys
+}
+
 func putEvent(e *Event) {
+	// Recorded values are not kept alive by the pool.
+	clear(e.recs)
+	e.recs = e.recs[:0]
+
 	eventPool.Put(e)
 }
 
 // AppendString appends a string to the event.
 func (e *Event) AppendString(k, s string) {
-	e.fmtr.AppendKey(e.buf, k)
-	e.fmtr.AppendString(e.buf, s)
+	if e.record {
+		e.recordField(k, s)
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, s)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
+	e.appendString(s)
+	e.endField()
+}
+
+// AppendStack appends a stack trace to the event. It is written as a
+// string, unless the formatter is a StackAppender.
+func (e *Event) AppendStack(k, s string) {
+	if e.record {
+		e.recordField(k, stackTrace(s))
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, s)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
+	if a, ok := e.fmtr.(StackAppender); ok {
+		if e.redact != nil && len(e.redact.patterns) > 0 {
+			s = e.redact.String(s)
+		}
+		a.AppendStack(e.buf, s)
+		e.endField()
+		return
+	}
+	e.appendString(s)
+	e.endField()
 }
 
 // AppendStrings appends strings to the event.
 func (e *Event) AppendStrings(k string, s []string) {
-	e.fmtr.AppendKey(e.buf, k)
+	if e.record {
+		e.recordField(k, slices.Clone(s))
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, s)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
 	e.fmtr.AppendArrayStart(e.buf)
 	for i, ss := range s {
 		if i > 0 {
 			e.fmtr.AppendArraySep(e.buf)
 		}
-		e.fmtr.AppendString(e.buf, ss)
+		e.appendString(ss)
 	}
 	e.fmtr.AppendArrayEnd(e.buf)
+	e.endField()
 }
 
 // AppendBytes appends bytes to the event.
 func (e *Event) AppendBytes(k string, p []byte) {
-	e.fmtr.AppendKey(e.buf, k)
+	if e.record {
+		e.recordField(k, slices.Clone(p))
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, p)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
+	if a, ok := e.fmtr.(BytesAppender); ok {
+		a.AppendBytes(e.buf, p)
+		e.endField()
+		return
+	}
 	e.fmtr.AppendArrayStart(e.buf)
 	for i, b := range p {
 		if i > 0 {
@@ -62,23 +250,62 @@ func (e *Event) AppendBytes(k string, p []byte) {
 		e.fmtr.AppendInt(e.buf, int64(b))
 	}
 	e.fmtr.AppendArrayEnd(e.buf)
+	e.endField()
 }
 
 // AppendBool appends a bool to the event.
 func (e *Event) AppendBool(k string, b bool) {
-	e.fmtr.AppendKey(e.buf, k)
+	if e.record {
+		e.recordField(k, b)
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, b)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
 	e.fmtr.AppendBool(e.buf, b)
+	e.endField()
 }
 
 // AppendInt appends an int to the event.
 func (e *Event) AppendInt(k string, i int64) {
-	e.fmtr.AppendKey(e.buf, k)
+	if e.record {
+		e.recordField(k, i)
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, i)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
 	e.fmtr.AppendInt(e.buf, i)
+	e.endField()
 }
 
 // AppendInts appends ints to the event.
 func (e *Event) AppendInts(k string, a []int) {
-	e.fmtr.AppendKey(e.buf, k)
+	if e.record {
+		e.recordField(k, slices.Clone(a))
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, a)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
 	e.fmtr.AppendArrayStart(e.buf)
 	for i, ii := range a {
 		if i > 0 {
@@ -87,34 +314,117 @@ func (e *Event) AppendInts(k string, a []int) {
 		e.fmtr.AppendInt(e.buf, int64(ii))
 	}
 	e.fmtr.AppendArrayEnd(e.buf)
+	e.endField()
 }
 
 // AppendUint appends a uint to the event.
 func (e *Event) AppendUint(k string, i uint64) {
-	e.fmtr.AppendKey(e.buf, k)
+	if e.record {
+		e.recordField(k, i)
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, i)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
 	e.fmtr.AppendUint(e.buf, i)
+	e.endField()
 }
 
 // AppendFloat appends a float to the event.
 func (e *Event) AppendFloat(k string, f float64) {
-	e.fmtr.AppendKey(e.buf, k)
+	if e.record {
+		e.recordField(k, f)
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, f)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
 	e.fmtr.AppendFloat(e.buf, f)
+	e.endField()
 }
 
 // AppendTime appends a time to the event.
 func (e *Event) AppendTime(k string, d time.Time) {
-	e.fmtr.AppendKey(e.buf, k)
+	if e.record {
+		e.recordField(k, d)
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, d)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
 	e.fmtr.AppendTime(e.buf, d)
+	e.endField()
 }
 
 // AppendDuration appends a duration to the event.
 func (e *Event) AppendDuration(k string, d time.Duration) {
-	e.fmtr.AppendKey(e.buf, k)
+	if e.record {
+		e.recordField(k, d)
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, d)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
 	e.fmtr.AppendDuration(e.buf, d)
+	e.endField()
 }
 
 // AppendInterface appends a interface to the event.
 func (e *Event) AppendInterface(k string, v any) {
-	e.fmtr.AppendKey(e.buf, k)
+	if e.record {
+		// Non-nil values are written as their string form by formatters.
+		if s, ok := formatSecrets(v); ok {
+			v = s
+		} else if v != nil {
+			v = fmt.Sprintf("%+v", v)
+		}
+		e.recordField(k, v)
+		return
+	}
+
+	if e.redact != nil && e.redact.matchKey(k) {
+		e.appendMasked(k, v)
+		return
+	}
+
+	if s, ok := formatSecrets(v); ok {
+		e.AppendString(k, s)
+		return
+	}
+
+	if !e.appendKey(k) {
+		return
+	}
+	if v != nil && e.redact != nil && len(e.redact.patterns) > 0 {
+		// Formatters write non-nil values as their string form.
+		e.fmtr.AppendString(e.buf, e.redact.String(fmt.Sprintf("%+v", v)))
+		e.endField()
+		return
+	}
 	e.fmtr.AppendInterface(e.buf, v)
+	e.endField()
 }
@@ -28,3 +28,24 @@ func ExampleWithContext() {
 	reqLog := log.FromContext(reqCtx)
 	reqLog.Info("request handled", ctx.Int("status", 200))
 }
+
+func ExampleLogger_Ctx() {
+	log := logger.New(os.Stdout, logger.LogfmtFormat(), logger.Info).With(ctx.Str("svc", "api"))
+
+	// The request context would usually carry the span started by the tracer.
+	reqCtx := logger.WithContext(context.Background(), log, ctx.Str("req_id", "abc-123"))
+
+	log.Ctx(reqCtx).Info("request handled", ctx.Int("status", 200))
+}
+
+func ExampleLogger_WithRedactor() {
+	r := logger.NewRedactor(
+		logger.RedactKeys("password", "*_token"),
+		logger.RedactValues(logger.EmailPattern),
+	)
+	log := logger.New(os.Stdout, logger.LogfmtFormat(), logger.Info).WithRedactor(r)
+
+	log.Info("user login", ctx.Str("user", "bob@example.com"), ctx.Str("password", "hunter2"))
+
+	// Output: lvl=info msg="user login" user=[REDACTED] password=[REDACTED]
+}
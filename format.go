@@ -1,9 +1,13 @@
 package logger
 
 import (
+	stdbytes "bytes"
 	"fmt"
+	"io"
+	"math"
 	"strings"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/hamba/logger/v2/internal/bytes"
@@ -38,11 +42,103 @@ type Formatter interface {
 	AppendInterface(buf *bytes.Buffer, v any)
 }
 
-type json struct{}
+// NonFinitePolicy is the way NaN and infinite float values are written.
+type NonFinitePolicy int
+
+// Non-finite float policies.
+const (
+	// NonFiniteString writes NaN, +Inf and -Inf, quoted in json.
+	NonFiniteString NonFinitePolicy = iota
+	// NonFiniteNull writes null.
+	NonFiniteNull
+)
+
+// FormatOption configures a formatter.
+type FormatOption func(*formatConfig)
+
+// WithFloatPrecision sets the number of decimals floats are written with
+// by the logfmt and console formatters. A precision of -1 writes the
+// fewest decimals needed to represent the value exactly. The json
+// formatter always writes the exact value.
+func WithFloatPrecision(prec int) FormatOption {
+	return func(c *formatConfig) {
+		c.floatPrec = max(prec, -1)
+	}
+}
+
+// WithNonFinite sets the way NaN and infinite float values are written.
+func WithNonFinite(policy NonFinitePolicy) FormatOption {
+	return func(c *formatConfig) {
+		c.nonFinite = policy
+	}
+}
+
+// WithTimeLayout sets the layout the console formatters write times with,
+// instead of TimeFormatConsole.
+func WithTimeLayout(layout string) FormatOption {
+	return func(c *formatConfig) {
+		c.timeLayout = layout
+	}
+}
+
+type formatConfig struct {
+	floatPrec  int
+	timeLayout string
+	nonFinite  NonFinitePolicy
+	color      colorMode
+	colorOut   io.Writer
+	palette    *Palette
+	msgWidth   int
+	relStart   time.Time
+	traceKeys  TraceKeys
+}
+
+func newFormatConfig(opts []FormatOption) formatConfig {
+	cfg := formatConfig{floatPrec: 3, msgWidth: 40, traceKeys: DefaultTraceKeys}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// appendFloat appends a float, writing non-finite values by the policy.
+func (c formatConfig) appendFloat(buf *bytes.Buffer, f float64, quote bool) {
+	if !math.IsNaN(f) && !math.IsInf(f, 0) {
+		buf.AppendFloat(f, 'f', c.floatPrec, 64)
+		return
+	}
+
+	if c.nonFinite == NonFiniteNull {
+		buf.WriteString("null")
+		return
+	}
+
+	if quote {
+		buf.WriteByte('"')
+	}
+	switch {
+	case math.IsNaN(f):
+		buf.WriteString("NaN")
+	case f > 0:
+		buf.WriteString("+Inf")
+	default:
+		buf.WriteString("-Inf")
+	}
+	if quote {
+		buf.WriteByte('"')
+	}
+}
+
+type json struct {
+	cfg formatConfig
+}
 
 // JSONFormat formats a log line in json format.
-func JSONFormat() Formatter {
-	return &json{}
+//
+// NaN and infinite floats are not valid json numbers and are written
+// as strings unless configured otherwise.
+func JSONFormat(opts ...FormatOption) Formatter {
+	return &json{cfg: newFormatConfig(opts)}
 }
 
 func (j *json) WriteMessage(buf *bytes.Buffer, ts time.Time, lvl Level, msg string) {
@@ -83,9 +179,9 @@ func (j *json) AppendArrayEnd(buf *bytes.Buffer) {
 }
 
 func (j *json) AppendKey(buf *bytes.Buffer, key string) {
-	buf.WriteString(`,"`)
-	buf.WriteString(key)
-	buf.WriteString(`":`)
+	buf.WriteByte(',')
+	appendString(buf, key, true)
+	buf.WriteByte(':')
 }
 
 func (j *json) AppendString(buf *bytes.Buffer, s string) {
@@ -105,7 +201,12 @@ func (j *json) AppendUint(buf *bytes.Buffer, i uint64) {
 }
 
 func (j *json) AppendFloat(buf *bytes.Buffer, f float64) {
-	buf.AppendFloat(f, 'g', -1, 64)
+	if !math.IsNaN(f) && !math.IsInf(f, 0) {
+		buf.AppendFloat(f, 'g', -1, 64)
+		return
+	}
+
+	j.cfg.appendFloat(buf, f, true)
 }
 
 func (j *json) AppendTime(buf *bytes.Buffer, t time.Time) {
@@ -134,17 +235,46 @@ func (j *json) AppendInterface(buf *bytes.Buffer, v any) {
 	j.AppendString(buf, fmt.Sprintf("%+v", v))
 }
 
-type logfmt struct{}
+type logfmt struct {
+	cfg formatConfig
+}
 
 // LogfmtFormat formats a log line in logfmt format.
-func LogfmtFormat() Formatter {
-	return &logfmt{}
+//
+// Floats are written with 3 decimals unless configured otherwise.
+func LogfmtFormat(opts ...FormatOption) Formatter {
+	return &logfmt{cfg: newFormatConfig(opts)}
 }
 
 func (l *logfmt) needsQuote(s string) bool {
+	// Empty values are quoted, as many parsers read "key=" as a key
+	// without a value.
+	if s == "" {
+		return true
+	}
+	// The string null is quoted to tell it apart from a null value.
+	if s == "null" {
+		return true
+	}
+
+	ascii := true
 	for i := range len(s) {
 		b := s[i]
-		if b <= ' ' || b == '=' || b == '"' {
+		if b <= ' ' || b == '=' || b == '"' || b == '\\' || b == 0x7f {
+			return true
+		}
+		if b >= utf8.RuneSelf {
+			ascii = false
+		}
+	}
+	if ascii {
+		return false
+	}
+
+	// Invalid UTF-8 is escaped, which is only understood when quoted,
+	// while non-ASCII whitespace splits values in some parsers.
+	for _, r := range s {
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
 			return true
 		}
 	}
@@ -172,22 +302,76 @@ func (l *logfmt) AppendLineBreak(buf *bytes.Buffer) {
 	buf.WriteByte('\n')
 }
 
-func (l *logfmt) AppendArrayStart(_ *bytes.Buffer) {}
+// AppendArrayStart marks the start of the array, which is rewritten when
+// the array ends.
+func (l *logfmt) AppendArrayStart(buf *bytes.Buffer) {
+	buf.PushMark(buf.Len())
+}
 
 func (l *logfmt) AppendArraySep(buf *bytes.Buffer) {
 	buf.WriteByte(',')
 }
 
-func (l *logfmt) AppendArrayEnd(_ *bytes.Buffer) {}
+// AppendArrayEnd rewrites the array from its mark. Arrays with quoted
+// elements are quoted as a whole, escaping the elements, as parsers do not
+// read quoted elements.
+func (l *logfmt) AppendArrayEnd(buf *bytes.Buffer) {
+	start := buf.PopMark()
+	if start < 0 {
+		return
+	}
+
+	arr := buf.Bytes()[start:]
+	switch {
+	case len(arr) == 0:
+		buf.WriteString(`""`)
+		return
+	case stdbytes.IndexByte(arr, '"') < 0 || !hasArraySep(arr):
+		return
+	}
+
+	elems := stdbytes.Clone(arr)
+	buf.Truncate(start)
+	buf.WriteByte('"')
+	for _, c := range elems {
+		if c == '"' || c == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(c)
+	}
+	buf.WriteByte('"')
+}
+
+// hasArraySep reports whether the array has a separator outside of
+// quoted elements.
+func hasArraySep(arr []byte) bool {
+	inQuote := false
+	for i := 0; i < len(arr); i++ {
+		switch arr[i] {
+		case '"':
+			inQuote = !inQuote
+		case '\\':
+			i++
+		case ',':
+			if !inQuote {
+				return true
+			}
+		}
+	}
+	return false
+}
 
 func (l *logfmt) AppendKey(buf *bytes.Buffer, key string) {
 	buf.WriteByte(' ')
-	buf.WriteString(key)
+	appendLogfmtKey(buf, key)
 	buf.WriteByte('=')
 }
 
 func (l *logfmt) AppendString(buf *bytes.Buffer, s string) {
-	appendString(buf, s, l.needsQuote(s))
+	// Array elements holding a comma are quoted to keep them apart.
+	quote := l.needsQuote(s) ||
+		strings.IndexByte(s, ',') >= 0 && buf.PeekMark() >= 0
+	appendString(buf, s, quote)
 }
 
 func (l *logfmt) AppendBool(buf *bytes.Buffer, b bool) {
@@ -203,7 +387,7 @@ func (l *logfmt) AppendUint(buf *bytes.Buffer, i uint64) {
 }
 
 func (l *logfmt) AppendFloat(buf *bytes.Buffer, f float64) {
-	buf.AppendFloat(f, 'f', 3, 64)
+	l.cfg.appendFloat(buf, f, false)
 }
 
 func (l *logfmt) AppendTime(buf *bytes.Buffer, t time.Time) {
@@ -227,78 +411,49 @@ func (l *logfmt) AppendInterface(buf *bytes.Buffer, v any) {
 	l.AppendString(buf, fmt.Sprintf("%+v", v))
 }
 
-const (
-	// Foreground text colors.
-	_ = iota + 30
-	colorRed
-	colorGreen
-	colorYellow
-	colorBlue
-	_
-	colorCyan
-	colorWhite
-
-	colorReset = 0
-	colorBold  = 1
-)
-
-var noColor = newColor(colorReset)
-
-type color []int
-
-func newColor(attr ...int) color {
-	return attr
+type console struct {
+	cfg     formatConfig
+	color   bool
+	palette Palette
 }
 
-func (c color) Write(buf *bytes.Buffer) {
-	buf.WriteByte('\x1b')
-	buf.WriteByte('[')
-	for i := range c {
-		if i > 0 {
-			buf.WriteByte(';')
-		}
-		buf.AppendInt(int64(c[i]))
+// ConsoleFormat formats a log line in a console format.
+//
+// Colors are written unless disabled with WithColor, WithColorAuto or the
+// NO_COLOR environment variable.
+// Floats are written with 3 decimals and times in TimeFormatConsole unless
+// configured otherwise.
+func ConsoleFormat(opts ...FormatOption) Formatter {
+	cfg := newFormatConfig(opts)
+
+	palette := DefaultPalette()
+	if cfg.palette != nil {
+		palette = *cfg.palette
 	}
-	buf.WriteByte('m')
-}
-
-func withColor(c color, buf *bytes.Buffer, fn func()) {
-	c.Write(buf)
-	fn()
-	noColor.Write(buf)
-}
-
-type console struct{}
 
-// ConsoleFormat formats a log line in a console format.
-func ConsoleFormat() Formatter {
-	return &console{}
+	return &console{
+		cfg:     cfg,
+		color:   cfg.useColor(),
+		palette: palette,
+	}
 }
 
-func (c *console) lvlColor(lvl Level) color {
-	switch lvl {
-	case Crit:
-		return newColor(colorRed, colorBold)
-	case Error:
-		return newColor(colorRed)
-	case Warn:
-		return newColor(colorYellow)
-	case Info:
-		return newColor(colorGreen)
-	case Debug:
-		return newColor(colorBlue)
+func (c *console) withColor(col Color, buf *bytes.Buffer, fn func()) {
+	if !c.color || len(col) == 0 {
+		fn()
+		return
 	}
-	return newColor(colorWhite)
+	withColor(col, buf, fn)
 }
 
 func (c *console) WriteMessage(buf *bytes.Buffer, ts time.Time, lvl Level, msg string) {
 	if !ts.IsZero() {
-		withColor(newColor(colorBlue), buf, func() {
+		c.withColor(c.palette.Time, buf, func() {
 			c.AppendTime(buf, ts)
 		})
 		buf.WriteByte(' ')
 	}
-	withColor(c.lvlColor(lvl), buf, func() {
+	c.withColor(c.palette.Levels[lvl], buf, func() {
 		buf.WriteString(strings.ToUpper(lvl.String()))
 	})
 	buf.WriteByte(' ')
@@ -324,13 +479,8 @@ func (c *console) AppendArrayEnd(_ *bytes.Buffer) {}
 func (c *console) AppendKey(buf *bytes.Buffer, key string) {
 	buf.WriteByte(' ')
 
-	col := newColor(colorCyan)
-	if strings.HasPrefix(key, "err") {
-		col = newColor(colorRed)
-	}
-
-	withColor(col, buf, func() {
-		buf.WriteString(key)
+	c.withColor(c.palette.keyColor(key), buf, func() {
+		appendLogfmtKey(buf, key)
 		buf.WriteByte('=')
 	})
 }
@@ -352,11 +502,15 @@ func (c *console) AppendUint(buf *bytes.Buffer, i uint64) {
 }
 
 func (c *console) AppendFloat(buf *bytes.Buffer, f float64) {
-	buf.AppendFloat(f, 'f', 3, 64)
+	c.cfg.appendFloat(buf, f, false)
 }
 
 func (c *console) AppendTime(buf *bytes.Buffer, t time.Time) {
-	buf.AppendTime(t, TimeFormatConsole)
+	layout := c.cfg.timeLayout
+	if layout == "" {
+		layout = TimeFormatConsole
+	}
+	buf.AppendTime(t, layout)
 }
 
 func (c *console) AppendDuration(buf *bytes.Buffer, d time.Duration) {
@@ -421,6 +575,40 @@ func appendString(buf *bytes.Buffer, s string, quote bool) {
 	}
 }
 
+// appendLogfmtKey appends a logfmt key, replacing any characters that
+// would end the key, or that are not printable, with underscores.
+func appendLogfmtKey(buf *bytes.Buffer, key string) {
+	if key == "" {
+		buf.WriteByte('_')
+		return
+	}
+
+	start := 0
+	for i := 0; i < len(key); {
+		b := key[i]
+		if b > ' ' && b < utf8.RuneSelf && b != '=' && b != '"' && b != 0x7f {
+			i++
+			continue
+		}
+
+		size := 1
+		if b >= utf8.RuneSelf {
+			var r rune
+			r, size = utf8.DecodeRuneInString(key[i:])
+			if r != utf8.RuneError && unicode.IsGraphic(r) && !unicode.IsSpace(r) {
+				i += size
+				continue
+			}
+		}
+
+		buf.WriteString(key[start:i])
+		buf.WriteByte('_')
+		i += size
+		start = i
+	}
+	buf.WriteString(key[start:])
+}
+
 func tryAddASCII(buf *bytes.Buffer, b byte) bool {
 	if b >= utf8.RuneSelf {
 		return false
@@ -442,3 +630,19 @@ func tryAddASCII(buf *bytes.Buffer, b byte) bool {
 	}
 	return true
 }
+
+func hasSuffix(b []byte, suffix string) bool {
+	return len(b) >= len(suffix) && string(b[len(b)-len(suffix):]) == suffix
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
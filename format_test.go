@@ -1,12 +1,21 @@
 package logger_test
 
 import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/go-logfmt/logfmt"
 	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
 	"github.com/hamba/logger/v2/internal/bytes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestJsonFormat(t *testing.T) {
@@ -35,6 +44,46 @@ func TestJsonFormat_Array(t *testing.T) {
 	assert.Equal(t, "[,]", string(buf.Bytes()))
 }
 
+func TestJsonFormat_Key(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "plain",
+			in:   "key",
+			want: `,"key":`,
+		},
+		{
+			name: "quote and backslash",
+			in:   `a"b\c`,
+			want: `,"a\"b\\c":`,
+		},
+		{
+			name: "control characters",
+			in:   "a\nb\x00",
+			want: `,"a\nb\u0000":`,
+		},
+		{
+			name: "invalid utf8",
+			in:   "a\xffb",
+			want: `,"a\ufffdb":`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fmtr := logger.JSONFormat()
+
+			buf := bytes.NewBuffer(512)
+			fmtr.AppendKey(buf, test.in)
+
+			assert.Equal(t, test.want, string(buf.Bytes()))
+		})
+	}
+}
+
 func TestJsonFormat_Strings(t *testing.T) {
 	tests := []struct {
 		name string
@@ -46,6 +95,11 @@ func TestJsonFormat_Strings(t *testing.T) {
 			in:   "=",
 			want: `"="`,
 		},
+		{
+			name: "null",
+			in:   "null",
+			want: `"null"`,
+		},
 		{
 			name: "quote",
 			in:   "\"",
@@ -127,6 +181,60 @@ func TestJsonFormat_Float(t *testing.T) {
 	assert.Equal(t, "4.56", string(buf.Bytes()))
 }
 
+func TestJsonFormat_NonFiniteFloat(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []logger.FormatOption
+		in   float64
+		want string
+	}{
+		{
+			name: "nan",
+			in:   math.NaN(),
+			want: `"NaN"`,
+		},
+		{
+			name: "positive inf",
+			in:   math.Inf(1),
+			want: `"+Inf"`,
+		},
+		{
+			name: "negative inf",
+			in:   math.Inf(-1),
+			want: `"-Inf"`,
+		},
+		{
+			name: "nan null",
+			opts: []logger.FormatOption{logger.WithNonFinite(logger.NonFiniteNull)},
+			in:   math.NaN(),
+			want: `null`,
+		},
+		{
+			name: "inf null",
+			opts: []logger.FormatOption{logger.WithNonFinite(logger.NonFiniteNull)},
+			in:   math.Inf(1),
+			want: `null`,
+		},
+		{
+			name: "precision ignored",
+			opts: []logger.FormatOption{logger.WithFloatPrecision(1)},
+			in:   4.5678,
+			want: `4.5678`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fmtr := logger.JSONFormat(test.opts...)
+
+			buf := bytes.NewBuffer(512)
+			fmtr.AppendFloat(buf, test.in)
+
+			assert.Equal(t, test.want, string(buf.Bytes()))
+		})
+	}
+}
+
 func TestJsonFormat_Time(t *testing.T) {
 	fmtr := logger.JSONFormat()
 
@@ -197,6 +305,125 @@ func TestLogfmtFormat_Array(t *testing.T) {
 	assert.Equal(t, ",", string(buf.Bytes()))
 }
 
+func TestLogfmtFormat_Arrays(t *testing.T) {
+	tests := []struct {
+		name  string
+		field logger.Field
+		want  string
+	}{
+		{
+			name:  "strings",
+			field: ctx.Strs("k", []string{"a", "b"}),
+			want:  `k=a,b`,
+		},
+		{
+			name:  "empty",
+			field: ctx.Strs("k", []string{}),
+			want:  `k=""`,
+		},
+		{
+			name:  "empty element",
+			field: ctx.Strs("k", []string{"a", ""}),
+			want:  `k="a,\"\""`,
+		},
+		{
+			name:  "quoted element",
+			field: ctx.Strs("k", []string{"a", "b c"}),
+			want:  `k="a,\"b c\""`,
+		},
+		{
+			name:  "escaped element",
+			field: ctx.Strs("k", []string{`say "hi"`, `back\slash`, "x"}),
+			want:  `k="\"say \\\"hi\\\"\",\"back\\\\slash\",x"`,
+		},
+		{
+			name:  "comma element",
+			field: ctx.Strs("k", []string{"a,b", "c"}),
+			want:  `k="\"a,b\",c"`,
+		},
+		{
+			name:  "single comma element",
+			field: ctx.Strs("k", []string{"a,b"}),
+			want:  `k="a,b"`,
+		},
+		{
+			name:  "bytes",
+			field: ctx.Bytes("k", []byte{1, 2}),
+			want:  `k=1,2`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sb strings.Builder
+			log := logger.New(&sb, logger.LogfmtFormat(), logger.Info)
+
+			log.Info("msg", test.field, ctx.Int("after", 1))
+
+			assert.Equal(t, "lvl=info msg=msg "+test.want+" after=1\n", sb.String())
+		})
+	}
+}
+
+func TestLogfmtFormat_CommaStringAfterArray(t *testing.T) {
+	var sb strings.Builder
+	log := logger.New(&sb, logger.LogfmtFormat(), logger.Info).With(ctx.Strs("tags", []string{"a", "b"}))
+
+	log.Info("msg", ctx.Str("k", "a,b"), ctx.Strs("l", []string{"c,d", "e"}))
+
+	assert.Equal(t, `lvl=info msg=msg tags=a,b k=a,b l="\"c,d\",e"`+"\n", sb.String())
+}
+
+func TestLogfmtFormat_Key(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "plain",
+			in:   "key",
+			want: " key=",
+		},
+		{
+			name: "unicode",
+			in:   "clé",
+			want: " clé=",
+		},
+		{
+			name: "empty",
+			in:   "",
+			want: " _=",
+		},
+		{
+			name: "separators",
+			in:   `a b=c"d`,
+			want: " a_b_c_d=",
+		},
+		{
+			name: "control characters",
+			in:   "a\nb\x7f",
+			want: " a_b_=",
+		},
+		{
+			name: "unicode whitespace and invalid utf8",
+			in:   "a\u00a0b\xffc",
+			want: " a_b_c=",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fmtr := logger.LogfmtFormat()
+
+			buf := bytes.NewBuffer(512)
+			fmtr.AppendKey(buf, test.in)
+
+			assert.Equal(t, test.want, string(buf.Bytes()))
+		})
+	}
+}
+
 func TestLogfmtFormat_Strings(t *testing.T) {
 	tests := []struct {
 		name string
@@ -231,13 +458,33 @@ func TestLogfmtFormat_Strings(t *testing.T) {
 		{
 			name: "escape",
 			in:   string('\\'),
-			want: `\\`,
+			want: `"\\"`,
 		},
 		{
 			name: "special chars",
 			in:   "some string with \"special ❤️ chars\" and somewhat realistic length",
 			want: `"some string with \"special ❤️ chars\" and somewhat realistic length"`,
 		},
+		{
+			name: "empty",
+			in:   "",
+			want: `""`,
+		},
+		{
+			name: "unicode whitespace",
+			in:   "a\u00a0b",
+			want: "\"a\u00a0b\"",
+		},
+		{
+			name: "unicode format character",
+			in:   "a\u200bb",
+			want: "\"a\u200bb\"",
+		},
+		{
+			name: "unicode letters",
+			in:   "héllo",
+			want: `héllo`,
+		},
 	}
 
 	for _, test := range tests {
@@ -289,6 +536,60 @@ func TestLogfmtFormat_Float(t *testing.T) {
 	assert.Equal(t, "4.560", string(buf.Bytes()))
 }
 
+func TestLogfmtFormat_NonFiniteFloat(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []logger.FormatOption
+		in   float64
+		want string
+	}{
+		{
+			name: "nan",
+			in:   math.NaN(),
+			want: `NaN`,
+		},
+		{
+			name: "positive inf",
+			in:   math.Inf(1),
+			want: `+Inf`,
+		},
+		{
+			name: "negative inf",
+			in:   math.Inf(-1),
+			want: `-Inf`,
+		},
+		{
+			name: "nan null",
+			opts: []logger.FormatOption{logger.WithNonFinite(logger.NonFiniteNull)},
+			in:   math.NaN(),
+			want: `null`,
+		},
+		{
+			name: "precision",
+			opts: []logger.FormatOption{logger.WithFloatPrecision(1)},
+			in:   4.56,
+			want: `4.6`,
+		},
+		{
+			name: "shortest precision",
+			opts: []logger.FormatOption{logger.WithFloatPrecision(-1)},
+			in:   4.5678,
+			want: `4.5678`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fmtr := logger.LogfmtFormat(test.opts...)
+
+			buf := bytes.NewBuffer(512)
+			fmtr.AppendFloat(buf, test.in)
+
+			assert.Equal(t, test.want, string(buf.Bytes()))
+		})
+	}
+}
+
 func TestLogfmtFormat_Time(t *testing.T) {
 	fmtr := logger.LogfmtFormat()
 
@@ -348,6 +649,20 @@ func TestConsoleFormat(t *testing.T) {
 	assert.Equal(t, want, string(buf.Bytes()))
 }
 
+func TestConsoleFormat_WithoutColor(t *testing.T) {
+	fmtr := logger.ConsoleFormat(logger.WithColor(false))
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendBeginMarker(buf)
+	fmtr.WriteMessage(buf, time.Unix(123, 0).UTC(), logger.Error, "some message")
+	fmtr.AppendKey(buf, "error")
+	fmtr.AppendString(buf, "some error")
+	fmtr.AppendEndMarker(buf)
+	fmtr.AppendLineBreak(buf)
+
+	assert.Equal(t, "12:02AM EROR some message error=some error\n", string(buf.Bytes()))
+}
+
 func TestConsoleFormat_Array(t *testing.T) {
 	fmtr := logger.ConsoleFormat()
 
@@ -359,6 +674,15 @@ func TestConsoleFormat_Array(t *testing.T) {
 	assert.Equal(t, ",", string(buf.Bytes()))
 }
 
+func TestConsoleFormat_Key(t *testing.T) {
+	fmtr := logger.ConsoleFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendKey(buf, "a b=\x1b[31m")
+
+	assert.Equal(t, " \x1b[36ma_b__[31m=\x1b[0m", string(buf.Bytes()))
+}
+
 func TestConsoleFormat_Strings(t *testing.T) {
 	tests := []struct {
 		name string
@@ -451,6 +775,49 @@ func TestConsoleFormat_Float(t *testing.T) {
 	assert.Equal(t, "4.560", string(buf.Bytes()))
 }
 
+func TestConsoleFormat_NonFiniteFloat(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []logger.FormatOption
+		in   float64
+		want string
+	}{
+		{
+			name: "nan",
+			in:   math.NaN(),
+			want: `NaN`,
+		},
+		{
+			name: "negative inf",
+			in:   math.Inf(-1),
+			want: `-Inf`,
+		},
+		{
+			name: "inf null",
+			opts: []logger.FormatOption{logger.WithNonFinite(logger.NonFiniteNull)},
+			in:   math.Inf(1),
+			want: `null`,
+		},
+		{
+			name: "precision",
+			opts: []logger.FormatOption{logger.WithFloatPrecision(0)},
+			in:   4.56,
+			want: `5`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fmtr := logger.ConsoleFormat(test.opts...)
+
+			buf := bytes.NewBuffer(512)
+			fmtr.AppendFloat(buf, test.in)
+
+			assert.Equal(t, test.want, string(buf.Bytes()))
+		})
+	}
+}
+
 func TestConsoleFormat_Time(t *testing.T) {
 	fmtr := logger.ConsoleFormat()
 
@@ -460,6 +827,15 @@ func TestConsoleFormat_Time(t *testing.T) {
 	assert.Equal(t, `6:54AM`, string(buf.Bytes()))
 }
 
+func TestConsoleFormat_TimeLayout(t *testing.T) {
+	fmtr := logger.ConsoleFormat(logger.WithTimeLayout(time.TimeOnly))
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendTime(buf, time.Unix(1541573670, 0).UTC())
+
+	assert.Equal(t, `06:54:30`, string(buf.Bytes()))
+}
+
 func TestConsoleFormat_Duration(t *testing.T) {
 	fmtr := logger.ConsoleFormat()
 
@@ -482,3 +858,160 @@ func TestConsoleFormat_Interface(t *testing.T) {
 
 	assert.Equal(t, `{Name:test}`, string(buf.Bytes()))
 }
+
+func FuzzJSONFormat(f *testing.F) {
+	f.Add("key", "value")
+	f.Add(`a"b\c`, "line\nbreak")
+	f.Add("\x00\x1f", "  ")
+	f.Add("lvl", "\xff")
+
+	f.Fuzz(func(t *testing.T, key, val string) {
+		var sb strings.Builder
+		log := logger.New(&sb, logger.JSONFormat(), logger.Info)
+
+		log.Info("msg", ctx.Str(key, val))
+
+		var got map[string]any
+		require.NoError(t, json.Unmarshal([]byte(sb.String()), &got), sb.String())
+		if !utf8.ValidString(key) || !utf8.ValidString(val) || key == logger.LevelKey || key == logger.MessageKey {
+			return
+		}
+		assert.Equal(t, val, got[key])
+	})
+}
+
+// TestLogfmtFormat_Conformance checks values are read back as written
+// by a reference parser, and are only left unquoted when they hold no
+// whitespace that would split them in other parsers.
+func TestLogfmtFormat_Conformance(t *testing.T) {
+	tests := []struct {
+		name  string
+		field logger.Field
+		want  string
+	}{
+		{name: "plain", field: ctx.Str("k", "value"), want: "value"},
+		{name: "empty", field: ctx.Str("k", ""), want: ""},
+		{name: "space", field: ctx.Str("k", "a b"), want: "a b"},
+		{name: "leading space", field: ctx.Str("k", " a"), want: " a"},
+		{name: "equals", field: ctx.Str("k", "a=b"), want: "a=b"},
+		{name: "only equals", field: ctx.Str("k", "="), want: "="},
+		{name: "quote", field: ctx.Str("k", `a"b`), want: `a"b`},
+		{name: "backslash", field: ctx.Str("k", `a\b`), want: `a\b`},
+		{name: "trailing backslash", field: ctx.Str("k", `a\`), want: `a\`},
+		{name: "escaped quote", field: ctx.Str("k", `\"`), want: `\"`},
+		{name: "newline", field: ctx.Str("k", "a\nb"), want: "a\nb"},
+		{name: "carriage return", field: ctx.Str("k", "a\rb"), want: "a\rb"},
+		{name: "tab", field: ctx.Str("k", "a\tb"), want: "a\tb"},
+		{name: "control character", field: ctx.Str("k", "a\x01b"), want: "a\x01b"},
+		{name: "delete", field: ctx.Str("k", "a\x7fb"), want: "a\x7fb"},
+		{name: "no-break space", field: ctx.Str("k", "a\u00a0b"), want: "a\u00a0b"},
+		{name: "line separator", field: ctx.Str("k", "a\u2028b"), want: "a\u2028b"},
+		{name: "ideographic space", field: ctx.Str("k", "a\u3000b"), want: "a\u3000b"},
+		{name: "zero width space", field: ctx.Str("k", "a\u200bb"), want: "a\u200bb"},
+		{name: "emoji", field: ctx.Str("k", "❤️"), want: "❤️"},
+		{name: "combining mark", field: ctx.Str("k", "e\u0301"), want: "e\u0301"},
+		{name: "invalid utf8", field: ctx.Str("k", "a\xffb"), want: "a\ufffdb"},
+		{name: "null", field: ctx.Str("k", "null"), want: "null"},
+		{name: "comma", field: ctx.Str("k", "a,b"), want: "a,b"},
+		{name: "strings", field: ctx.Strs("k", []string{"a", "b c", ""}), want: `a,"b c",""`},
+		{name: "strings with comma", field: ctx.Strs("k", []string{"a,b", "c"}), want: `"a,b",c`},
+		{name: "empty strings", field: ctx.Strs("k", nil), want: ""},
+		{name: "bytes", field: ctx.Bytes("k", []byte{1, 2}), want: "1,2"},
+		{name: "interface", field: ctx.Interface("k", struct{ A string }{A: "b c"}), want: "{A:b c}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sb strings.Builder
+			log := logger.New(&sb, logger.LogfmtFormat(), logger.Info)
+
+			log.Info("msg", test.field, ctx.Str("after", "x"))
+
+			dec := logfmt.NewDecoder(strings.NewReader(sb.String()))
+			require.True(t, dec.ScanRecord(), sb.String())
+			var pairs [][2]string
+			for dec.ScanKeyval() {
+				pairs = append(pairs, [2]string{string(dec.Key()), string(dec.Value())})
+			}
+			require.NoError(t, dec.Err(), sb.String())
+			want := [][2]string{{"lvl", "info"}, {"msg", "msg"}, {"k", test.want}, {"after", "x"}}
+			assert.Equal(t, want, pairs, sb.String())
+
+			val := strings.TrimSuffix(strings.TrimPrefix(sb.String(), "lvl=info msg=msg k="), " after=x\n")
+			if !strings.HasPrefix(val, `"`) {
+				assert.NotEmpty(t, val)
+				assert.Equal(t, -1, strings.IndexFunc(val, unicode.IsSpace), val)
+			}
+		})
+	}
+}
+
+func FuzzLogfmtFormat(f *testing.F) {
+	f.Add("key", "value")
+	f.Add("a b=c", `quoted "value"`)
+	f.Add("", "")
+	f.Add("\x7f", "\\back\\slash")
+	f.Add("k", "\xff")
+
+	f.Fuzz(func(t *testing.T, key, val string) {
+		var sb strings.Builder
+		log := logger.New(&sb, logger.LogfmtFormat(), logger.Info)
+
+		log.Info("msg", ctx.Str(key, val), ctx.Strs("strs", []string{val, val}))
+
+		dec := logfmt.NewDecoder(strings.NewReader(sb.String()))
+		require.True(t, dec.ScanRecord(), sb.String())
+
+		var pairs [][2]string
+		for dec.ScanKeyval() {
+			pairs = append(pairs, [2]string{string(dec.Key()), string(dec.Value())})
+		}
+		require.NoError(t, dec.Err(), sb.String())
+		require.Len(t, pairs, 4, sb.String())
+		assert.False(t, dec.ScanRecord())
+
+		if !utf8.ValidString(val) {
+			return
+		}
+		assert.Equal(t, val, pairs[2][1], sb.String())
+		assert.Equal(t, []string{val, val}, splitLogfmtArray(t, pairs[3][1]), sb.String())
+	})
+}
+
+// splitLogfmtArray splits a logfmt array value read by a reference parser
+// into its elements.
+func splitLogfmtArray(t *testing.T, s string) []string {
+	t.Helper()
+
+	var elems []string
+	for {
+		var elem string
+		if strings.HasPrefix(s, `"`) {
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+			require.Less(t, i, len(s), s)
+
+			var err error
+			elem, err = strconv.Unquote(s[:i+1])
+			require.NoError(t, err, s)
+			s = s[i+1:]
+		} else {
+			i := strings.IndexByte(s, ',')
+			if i < 0 {
+				i = len(s)
+			}
+			elem, s = s[:i], s[i:]
+		}
+		elems = append(elems, elem)
+
+		if s == "" {
+			return elems
+		}
+		require.True(t, strings.HasPrefix(s, ","), s)
+		s = s[1:]
+	}
+}
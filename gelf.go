@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/hamba/logger/v2/internal/bytes"
+)
+
+// GELFVersion is the GELF specification version written by the gelf formatter.
+const GELFVersion = "1.1"
+
+type gelf struct {
+	json
+
+	host string
+}
+
+// GELFFormat formats a log line in the Graylog Extended Log Format (GELF).
+//
+// The level is written as its syslog severity, the timestamp as fractional
+// seconds and all context fields are prefixed with an underscore. Characters
+// not allowed in GELF field names are replaced with underscores. The key
+// "id" is written as "_id_", as "_id" is reserved by GELF.
+//
+// GELF fields may only hold strings and numbers, so bools and nulls are
+// written as strings and arrays as strings holding their JSON.
+func GELFFormat(host string) Formatter {
+	return &gelf{host: host}
+}
+
+func (g *gelf) WriteMessage(buf *bytes.Buffer, ts time.Time, lvl Level, msg string) {
+	buf.WriteString(`"version":"` + GELFVersion + `","host":`)
+	appendString(buf, g.host, true)
+	buf.WriteString(`,"short_message":`)
+	appendString(buf, msg, true)
+	if !ts.IsZero() {
+		buf.WriteString(`,"timestamp":`)
+		buf.AppendInt(ts.Unix())
+		buf.WriteByte('.')
+		ms := ts.Nanosecond() / int(time.Millisecond)
+		buf.WriteByte(byte('0' + ms/100))
+		buf.WriteByte(byte('0' + ms/10%10))
+		buf.WriteByte(byte('0' + ms%10))
+	}
+	buf.WriteString(`,"level":`)
+	buf.AppendInt(int64(syslogSeverity(lvl)))
+}
+
+func (g *gelf) AppendKey(buf *bytes.Buffer, key string) {
+	buf.WriteString(`,"_`)
+	for i := range len(key) {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '.', c == '-':
+		default:
+			c = '_'
+		}
+		buf.WriteByte(c)
+	}
+	if key == "id" {
+		buf.WriteByte('_')
+	}
+	buf.WriteString(`":`)
+}
+
+func (g *gelf) AppendArrayStart(buf *bytes.Buffer) {
+	// Mark the start of the outermost array, nested arrays marking it again.
+	i := buf.PeekMark()
+	if i < 0 {
+		i = buf.Len()
+	}
+	buf.PushMark(i)
+	buf.WriteByte('[')
+}
+
+func (g *gelf) AppendArrayEnd(buf *bytes.Buffer) {
+	buf.WriteByte(']')
+
+	start := buf.PopMark()
+	if start < 0 || buf.PeekMark() == start {
+		return
+	}
+	arr := string(buf.Bytes()[start:])
+	buf.Truncate(start)
+	appendString(buf, arr, true)
+}
+
+func (g *gelf) AppendBool(buf *bytes.Buffer, b bool) {
+	if buf.PeekMark() >= 0 {
+		buf.AppendBool(b)
+		return
+	}
+	buf.WriteByte('"')
+	buf.AppendBool(b)
+	buf.WriteByte('"')
+}
+
+func (g *gelf) AppendInterface(buf *bytes.Buffer, v any) {
+	if v == nil && buf.PeekMark() < 0 {
+		buf.WriteString(`""`)
+		return
+	}
+	g.json.AppendInterface(buf, v)
+}
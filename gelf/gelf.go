@@ -0,0 +1,250 @@
+// Package gelf implements writers that send GELF messages to Graylog.
+//
+// The writers are intended to be used with logger.GELFFormat:
+//
+//	w, err := gelf.NewUDPWriter("graylog:12201", gelf.WithCompression(gelf.Gzip))
+//	if err != nil {
+//		// Handle error.
+//	}
+//	log := logger.New(w, logger.GELFFormat(hostname), logger.Info)
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sync"
+)
+
+const (
+	// DefaultChunkSize is the default maximum UDP datagram size. It is
+	// chosen to fit in the MTU of most networks.
+	DefaultChunkSize = 1420
+
+	// maxChunks is the maximum number of chunks allowed by GELF.
+	maxChunks = 128
+	// chunkHeaderSize is the size of the GELF chunk header.
+	chunkHeaderSize = 12
+)
+
+// ErrTooLarge is returned when a message needs more than 128 chunks.
+var ErrTooLarge = errors.New("gelf: message too large")
+
+// Compression is a GELF UDP compression type.
+type Compression int
+
+// Compression types.
+const (
+	None Compression = iota
+	Gzip
+	Zlib
+)
+
+// UDPOption configures a UDP writer.
+type UDPOption func(*UDPWriter)
+
+// WithChunkSize sets the maximum datagram size, including the chunk header.
+func WithChunkSize(size int) UDPOption {
+	return func(w *UDPWriter) {
+		w.chunkSize = size
+	}
+}
+
+// WithCompression sets the compression applied to messages.
+func WithCompression(c Compression) UDPOption {
+	return func(w *UDPWriter) {
+		w.compression = c
+	}
+}
+
+// UDPWriter writes GELF messages over UDP, chunking messages that
+// are larger than the chunk size.
+type UDPWriter struct {
+	conn        net.Conn
+	chunkSize   int
+	compression Compression
+
+	pool sync.Pool
+}
+
+// NewUDPWriter returns a GELF UDP writer sending to addr.
+func NewUDPWriter(addr string, opts ...UDPOption) (*UDPWriter, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &UDPWriter{
+		conn:      conn,
+		chunkSize: DefaultChunkSize,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.chunkSize <= chunkHeaderSize {
+		_ = conn.Close()
+		return nil, errors.New("gelf: chunk size must be larger than the chunk header")
+	}
+
+	w.pool.New = func() any {
+		return &bytes.Buffer{}
+	}
+
+	return w, nil
+}
+
+// Write writes a GELF message.
+func (w *UDPWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	p = trimLineBreak(p)
+
+	buf := w.pool.Get().(*bytes.Buffer)
+	defer w.pool.Put(buf)
+	buf.Reset()
+
+	if w.compression != None {
+		if err = w.compress(buf, p); err != nil {
+			return 0, err
+		}
+		p = buf.Bytes()
+	}
+
+	if len(p) <= w.chunkSize {
+		if _, err = w.conn.Write(p); err != nil {
+			return 0, err
+		}
+		return n, nil
+	}
+
+	if err = w.writeChunks(p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (w *UDPWriter) compress(buf *bytes.Buffer, p []byte) error {
+	var cw io.WriteCloser
+	switch w.compression {
+	case Gzip:
+		cw = gzip.NewWriter(buf)
+	default:
+		cw = zlib.NewWriter(buf)
+	}
+
+	if _, err := cw.Write(p); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+func (w *UDPWriter) writeChunks(p []byte) error {
+	size := w.chunkSize - chunkHeaderSize
+	count := (len(p) + size - 1) / size
+	if count > maxChunks {
+		return ErrTooLarge
+	}
+
+	chunk := make([]byte, w.chunkSize)
+	chunk[0], chunk[1] = 0x1e, 0x0f
+	id := rand.Uint64()
+	for i := range 8 {
+		chunk[2+i] = byte(id >> (56 - 8*i))
+	}
+	chunk[11] = byte(count)
+
+	for i := range count {
+		chunk[10] = byte(i)
+		n := copy(chunk[chunkHeaderSize:], p[i*size:])
+		if _, err := w.conn.Write(chunk[:chunkHeaderSize+n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the writer.
+func (w *UDPWriter) Close() error {
+	return w.conn.Close()
+}
+
+// TCPWriter writes null byte delimited GELF messages over TCP.
+//
+// If a write fails, the writer reconnects and retries the message once.
+// A message may be received twice if the connection failed after it was
+// sent.
+type TCPWriter struct {
+	addr string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// NewTCPWriter returns a GELF TCP writer connected to addr.
+func NewTCPWriter(addr string) (*TCPWriter, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TCPWriter{addr: addr, conn: conn}, nil
+}
+
+// Write writes a GELF message.
+func (w *TCPWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, net.ErrClosed
+	}
+
+	msg := trimLineBreak(p)
+	if w.conn != nil {
+		if err = w.write(msg); err == nil {
+			return len(p), nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	if w.conn, err = net.Dial("tcp", w.addr); err != nil {
+		w.conn = nil
+		return 0, err
+	}
+	if err = w.write(msg); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *TCPWriter) write(msg []byte) error {
+	bufs := net.Buffers{msg, []byte{0}}
+	_, err := bufs.WriteTo(w.conn)
+	return err
+}
+
+// Close closes the writer.
+func (w *TCPWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
+
+func trimLineBreak(p []byte) []byte {
+	if n := len(p); n > 0 && p[n-1] == '\n' {
+		return p[:n-1]
+	}
+	return p
+}
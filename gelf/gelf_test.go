@@ -0,0 +1,213 @@
+package gelf_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/gelf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUDPWriter(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression gelf.Compression
+		decompress  func(io.Reader) (io.Reader, error)
+	}{
+		{
+			name:        "none",
+			compression: gelf.None,
+			decompress:  func(r io.Reader) (io.Reader, error) { return r, nil },
+		},
+		{
+			name:        "gzip",
+			compression: gelf.Gzip,
+			decompress:  func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		},
+		{
+			name:        "zlib",
+			compression: gelf.Zlib,
+			decompress:  func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := listenUDP(t)
+
+			w, err := gelf.NewUDPWriter(conn.LocalAddr().String(), gelf.WithCompression(test.compression))
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = w.Close() })
+
+			log := logger.New(w, logger.GELFFormat("some-host"), logger.Info)
+
+			log.Info("some message", ctx.Str("user", "bob"))
+
+			p := make([]byte, 2048)
+			n, _, err := conn.ReadFrom(p)
+			require.NoError(t, err)
+
+			r, err := test.decompress(bytes.NewReader(p[:n]))
+			require.NoError(t, err)
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			want := `{"version":"1.1","host":"some-host","short_message":"some message","level":6,"_user":"bob"}`
+			assert.Equal(t, want, string(got))
+		})
+	}
+}
+
+func TestUDPWriter_Chunked(t *testing.T) {
+	conn := listenUDP(t)
+
+	w, err := gelf.NewUDPWriter(conn.LocalAddr().String(), gelf.WithChunkSize(32))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	msg := []byte(strings.Repeat("abcdefghij", 5) + "\n")
+	n, err := w.Write(msg)
+	require.NoError(t, err)
+	assert.Equal(t, len(msg), n)
+
+	var got []byte
+	var id []byte
+	for i := range 3 {
+		p := make([]byte, 64)
+		n, _, err := conn.ReadFrom(p)
+		require.NoError(t, err)
+
+		assert.Equal(t, []byte{0x1e, 0x0f}, p[:2])
+		if id == nil {
+			id = p[2:10]
+		}
+		assert.Equal(t, id, p[2:10])
+		assert.Equal(t, byte(i), p[10])
+		assert.Equal(t, byte(3), p[11])
+		got = append(got, p[12:n]...)
+	}
+	assert.Equal(t, string(msg[:len(msg)-1]), string(got))
+}
+
+func TestUDPWriter_TooLarge(t *testing.T) {
+	conn := listenUDP(t)
+
+	w, err := gelf.NewUDPWriter(conn.LocalAddr().String(), gelf.WithChunkSize(13))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	_, err = w.Write(bytes.Repeat([]byte("a"), 129))
+
+	assert.ErrorIs(t, err, gelf.ErrTooLarge)
+}
+
+func TestNewUDPWriter_InvalidChunkSize(t *testing.T) {
+	_, err := gelf.NewUDPWriter("127.0.0.1:12201", gelf.WithChunkSize(12))
+
+	assert.Error(t, err)
+}
+
+func TestTCPWriter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	w, err := gelf.NewTCPWriter(ln.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	log := logger.New(w, logger.GELFFormat("some-host"), logger.Info)
+
+	log.Info("first")
+	log.Warn("second")
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	first, err := r.ReadString(0)
+	require.NoError(t, err)
+	second, err := r.ReadString(0)
+	require.NoError(t, err)
+	assert.Equal(t, `{"version":"1.1","host":"some-host","short_message":"first","level":6}`+"\x00", first)
+	assert.Equal(t, `{"version":"1.1","host":"some-host","short_message":"second","level":4}`+"\x00", second)
+}
+
+func TestTCPWriter_Reconnects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	w, err := gelf.NewTCPWriter(ln.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	_ = conn.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			accepted <- c
+		}
+	}()
+
+	// Writes to the closed connection may succeed until it is reset.
+	var next net.Conn
+	for i := 0; next == nil; i++ {
+		require.Less(t, i, 100, "writer did not reconnect")
+
+		_, err = w.Write([]byte("msg"))
+		require.NoError(t, err)
+
+		select {
+		case next = <-accepted:
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	t.Cleanup(func() { _ = next.Close() })
+
+	_ = next.SetReadDeadline(time.Now().Add(5 * time.Second))
+	got, err := bufio.NewReader(next).ReadString(0)
+	require.NoError(t, err)
+	assert.Equal(t, "msg\x00", got)
+}
+
+func TestTCPWriter_Closed(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	w, err := gelf.NewTCPWriter(ln.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	_, err = w.Write([]byte("msg"))
+
+	assert.ErrorIs(t, err, net.ErrClosed)
+}
+
+func listenUDP(t *testing.T) net.PacketConn {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	return conn
+}
@@ -0,0 +1,129 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/internal/bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGELFFormat(t *testing.T) {
+	fmtr := logger.GELFFormat("some-host")
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendBeginMarker(buf)
+	fmtr.WriteMessage(buf, time.Unix(123, 45e6).UTC(), logger.Error, "some message")
+	fmtr.AppendKey(buf, "error")
+	fmtr.AppendString(buf, "some error")
+	fmtr.AppendKey(buf, "status")
+	fmtr.AppendInt(buf, 500)
+	fmtr.AppendEndMarker(buf)
+	fmtr.AppendLineBreak(buf)
+
+	want := `{"version":"1.1","host":"some-host","short_message":"some message","timestamp":123.045,"level":3,"_error":"some error","_status":500}` + "\n"
+	assert.Equal(t, want, string(buf.Bytes()))
+}
+
+func TestGELFFormat_WithoutTimestamp(t *testing.T) {
+	fmtr := logger.GELFFormat("some-host")
+
+	buf := bytes.NewBuffer(512)
+	fmtr.WriteMessage(buf, time.Time{}, logger.Info, "some \"message\"")
+
+	want := `"version":"1.1","host":"some-host","short_message":"some \"message\"","level":6`
+	assert.Equal(t, want, string(buf.Bytes()))
+}
+
+func TestGELFFormat_Keys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "valid",
+			in:   "http.status-code_1",
+			want: `,"_http.status-code_1":`,
+		},
+		{
+			name: "invalid chars",
+			in:   "user name/\"id\"",
+			want: `,"_user_name__id_":`,
+		},
+		{
+			name: "reserved id",
+			in:   "id",
+			want: `,"_id_":`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fmtr := logger.GELFFormat("some-host")
+
+			buf := bytes.NewBuffer(512)
+			fmtr.AppendKey(buf, test.in)
+
+			assert.Equal(t, test.want, string(buf.Bytes()))
+		})
+	}
+}
+
+func TestGELFFormat_Values(t *testing.T) {
+	fmtr := logger.GELFFormat("some-host")
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendArrayStart(buf)
+	fmtr.AppendString(buf, "a")
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendFloat(buf, 4.56)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendDuration(buf, time.Second)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendInterface(buf, nil)
+	fmtr.AppendArrayEnd(buf)
+
+	assert.Equal(t, `"[\"a\",4.56,\"1s\",null]"`, string(buf.Bytes()))
+}
+
+func TestGELFFormat_AdditionalFieldTypes(t *testing.T) {
+	var sb strings.Builder
+	log := logger.New(&sb, logger.GELFFormat("some-host"), logger.Info)
+
+	log.Info("some message",
+		ctx.Bool("bool", true),
+		ctx.Ints("ints", []int{1, 2}),
+		ctx.Bytes("bytes", []byte{1, 2}),
+		ctx.Strs("strs", []string{"a", "b"}),
+		ctx.Interface("nil", nil),
+		ctx.Str("str", "a"),
+		ctx.Int("int", 1),
+		ctx.Float64("float", 1.5),
+	)
+
+	var got map[string]any
+	err := json.Unmarshal([]byte(sb.String()), &got)
+
+	require.NoError(t, err)
+	for k, v := range got {
+		if !strings.HasPrefix(k, "_") {
+			continue
+		}
+		switch v.(type) {
+		case string, float64:
+		default:
+			t.Errorf("field %s has type %T", k, v)
+		}
+	}
+	assert.Equal(t, "true", got["_bool"])
+	assert.Equal(t, "[1,2]", got["_ints"])
+	assert.Equal(t, "[1,2]", got["_bytes"])
+	assert.Equal(t, `["a","b"]`, got["_strs"])
+	assert.Equal(t, "", got["_nil"])
+}
@@ -3,13 +3,20 @@ module github.com/hamba/logger/v2
 go 1.26.7
 
 require (
+	github.com/go-logfmt/logfmt v0.6.1
 	github.com/go-stack/stack v1.8.1
 	github.com/stretchr/testify v1.12.1
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	go.opentelemetry.io/otel v1.45.0
+	go.opentelemetry.io/otel/log v0.21.0
+	go.opentelemetry.io/otel/log/logtest v0.21.0
 	go.opentelemetry.io/otel/trace v1.45.0
+	golang.org/x/sys v0.48.0
 )
 
 require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
-	go.opentelemetry.io/otel v1.45.0 // indirect
+	github.com/google/go-cmp v0.7.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
 	go.yaml.in/yaml/v3 v3.0.5 // indirect
 )
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"context"
+	"time"
+)
+
+// Hook is called with each entry written by a Logger, allowing entries
+// to be mirrored elsewhere, such as onto a trace span.
+type Hook interface {
+	// Formatter returns the formatter entries are rendered with for the hook.
+	// It must return the same formatter on each call.
+	Formatter() Formatter
+
+	// Enabled reports whether the entry should be rendered and fired for
+	// the context given to Ctx and the level.
+	Enabled(ctx context.Context, lvl Level) bool
+
+	// Fire is called with the context given to Ctx, the level and the entry
+	// rendered with the hook formatter. The entry must not be retained.
+	Fire(ctx context.Context, lvl Level, p []byte)
+}
+
+// WithHook returns a new Logger that calls hook with each entry written.
+//
+// Fields already added to the logger, including the trace fields added
+// by Ctx, are rendered for the hook.
+func (l *Logger) WithHook(hook Hook) *Logger {
+	log := l.withCtx(l.ctx, l.ctxKeys)
+	log.hook = hook
+	log.hookCtx, log.hookKeys = l.render(hook.Formatter(), nil, nil, l.fields)
+	return log
+}
+
+// hookContext returns the context given to Ctx, if any.
+func (l *Logger) hookContext() context.Context {
+	if l.goCtx == nil {
+		return context.Background()
+	}
+	return l.goCtx
+}
+
+// fire renders the entry with the recorded fields for the hook and fires it.
+func (l *Logger) fire(goCtx context.Context, ts time.Time, msg string, lvl Level, fields []recordedField) {
+	e := l.newEvent(l.hook.Formatter())
+
+	e.fmtr.AppendBeginMarker(e.buf)
+	e.fmtr.WriteMessage(e.buf, ts, lvl, msg)
+	e.writeCtx(l.hookCtx, l.hookKeys)
+	e.replay(fields)
+
+	e.fmtr.AppendEndMarker(e.buf)
+	e.fmtr.AppendLineBreak(e.buf)
+
+	l.hook.Fire(goCtx, lvl, e.buf.Bytes())
+
+	putEvent(e)
+}
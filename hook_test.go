@@ -0,0 +1,142 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLogger_WithHook(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	hook := &testHook{fmtr: logger.JSONFormat()}
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info).
+		With(ctx.Str("before", "hook")).
+		WithHook(hook).
+		With(ctx.Str("svc", "api"))
+
+	goCtx := logger.WithContext(context.Background(), log, ctx.Str("req_id", "abc123"))
+	log.Ctx(goCtx).Info("handled", ctx.Int("status", 200))
+	log.Debug("filtered")
+
+	assert.Equal(t, "lvl=info msg=handled before=hook svc=api req_id=abc123 status=200\n", buf.String())
+	require.Len(t, hook.entries, 1)
+	assert.Equal(t, logger.Info, hook.entries[0].lvl)
+	assert.Equal(t, `{"lvl":"info","msg":"handled","before":"hook","svc":"api","req_id":"abc123","status":200}`+"\n", hook.entries[0].entry)
+	assert.Same(t, goCtx, hook.entries[0].ctx)
+}
+
+func TestLogger_WithHookWithoutContext(t *testing.T) {
+	t.Parallel()
+
+	hook := &testHook{fmtr: logger.LogfmtFormat()}
+	log := logger.New(&bytes.Buffer{}, logger.LogfmtFormat(), logger.Info).WithHook(hook)
+
+	log.Error("failed")
+
+	require.Len(t, hook.entries, 1)
+	assert.Equal(t, logger.Error, hook.entries[0].lvl)
+	assert.Equal(t, "lvl=eror msg=failed\n", hook.entries[0].entry)
+	assert.Equal(t, context.Background(), hook.entries[0].ctx)
+}
+
+func TestLogger_WithHookNotEnabled(t *testing.T) {
+	t.Parallel()
+
+	hook := &testHook{fmtr: logger.LogfmtFormat(), lvl: logger.Error}
+	log := logger.New(&bytes.Buffer{}, logger.LogfmtFormat(), logger.Info).WithHook(hook)
+
+	log.Info("skipped")
+	log.Error("failed")
+
+	require.Len(t, hook.entries, 1)
+	assert.Equal(t, "lvl=eror msg=failed\n", hook.entries[0].entry)
+}
+
+func TestLogger_WithHookCarriesContextFields(t *testing.T) {
+	t.Parallel()
+
+	hook := &testHook{fmtr: logger.JSONFormat()}
+	log := logger.New(&bytes.Buffer{}, logger.LogfmtFormat(), logger.Info)
+	goCtx := logger.WithContext(context.Background(), log, ctx.Str("req_id", "abc123"))
+
+	log.FromContext(goCtx).WithHook(hook).Info("handled")
+
+	require.Len(t, hook.entries, 1)
+	assert.Equal(t, `{"lvl":"info","msg":"handled","req_id":"abc123"}`+"\n", hook.entries[0].entry)
+}
+
+func TestLogger_WithHookEvaluatesFieldsOnce(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	hook := &testHook{fmtr: logger.LogfmtFormat()}
+	strs := []string{"a"}
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info).With(ctx.Strs("strs", strs))
+	strs[0] = "b"
+
+	calls := 0
+	counted := func(e *logger.Event) {
+		calls++
+		e.AppendInt("calls", int64(calls))
+	}
+
+	_, file, line, _ := runtime.Caller(0)
+	log.WithHook(hook).Info("handled", counted, ctx.Caller("caller"))
+	caller := file + ":" + strconv.Itoa(line+1)
+
+	want := "lvl=info msg=handled strs=a calls=1 caller=" + caller + "\n"
+	assert.Equal(t, want, buf.String())
+	require.Len(t, hook.entries, 1)
+	assert.Equal(t, want, hook.entries[0].entry)
+	assert.Equal(t, 1, calls)
+}
+
+func TestLogger_WithHookCarriesTraceFields(t *testing.T) {
+	t.Parallel()
+
+	hook := &testHook{fmtr: logger.LogfmtFormat()}
+	log := logger.New(&bytes.Buffer{}, logger.LogfmtFormat(), logger.Info)
+	goCtx := trace.ContextWithSpanContext(context.Background(), newSpanContext())
+
+	log.WithHook(hook).Ctx(goCtx).Info("after")
+	log.Ctx(goCtx).WithHook(hook).Info("before")
+
+	require.Len(t, hook.entries, 2)
+	traced := " trace_id=01000000000000000000000000000000 span_id=0200000000000000 trace_flags=01\n"
+	assert.Equal(t, "lvl=info msg=after"+traced, hook.entries[0].entry)
+	assert.Equal(t, "lvl=info msg=before"+traced, hook.entries[1].entry)
+}
+
+type hookEntry struct {
+	ctx   context.Context
+	lvl   logger.Level
+	entry string
+}
+
+type testHook struct {
+	fmtr    logger.Formatter
+	lvl     logger.Level
+	entries []hookEntry
+}
+
+func (h *testHook) Formatter() logger.Formatter {
+	return h.fmtr
+}
+
+func (h *testHook) Enabled(_ context.Context, lvl logger.Level) bool {
+	return h.lvl == logger.Disabled || lvl <= h.lvl
+}
+
+func (h *testHook) Fire(ctx context.Context, lvl logger.Level, p []byte) {
+	h.entries = append(h.entries, hookEntry{ctx: ctx, lvl: lvl, entry: string(p)})
+}
@@ -0,0 +1,176 @@
+// Package httplog implements net/http request logging.
+//
+// The middleware logs each request handled and stores the request fields
+// in the request context, where handlers can retrieve them:
+//
+//	h = httplog.Middleware(log, httplog.WithSkipPaths("/health"))(h)
+//
+//	func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+//		s.log.FromContext(r.Context()).Info("doing work")
+//	}
+package httplog
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+)
+
+// Keys of the fields logged by the middleware.
+const (
+	MethodKey    = "method"
+	PathKey      = "path"
+	PatternKey   = "pattern"
+	StatusKey    = "status"
+	SizeKey      = "size"
+	DurationKey  = "duration"
+	RemoteKey    = "remote_addr"
+	UserAgentKey = "user_agent"
+)
+
+// Option configures the middleware.
+type Option func(*config)
+
+// WithSkipPaths skips logging requests to the given paths, such as
+// health checks. The request fields are still stored in the context.
+func WithSkipPaths(paths ...string) Option {
+	return func(c *config) {
+		for _, p := range paths {
+			c.skip[p] = struct{}{}
+		}
+	}
+}
+
+// WithMessage sets the message requests are logged with.
+func WithMessage(msg string) Option {
+	return func(c *config) {
+		c.msg = msg
+	}
+}
+
+type config struct {
+	msg  string
+	skip map[string]struct{}
+}
+
+// Middleware returns a middleware that logs each request.
+//
+// The method and path are stored as fields in the request context with
+// WithContext. Requests are logged at info level, 4xx responses at warn
+// level and 5xx responses at error level.
+//
+// The route pattern is only logged when the request given to the middleware
+// is routed by a http.ServeMux, which sets Request.Pattern on it. This is the
+// case when the mux is the next handler, but not when a handler in between
+// passes a copy of the request, e.g. one made with Request.WithContext.
+func Middleware(log *logger.Logger, opts ...Option) func(http.Handler) http.Handler {
+	cfg := config{
+		msg:  "http request",
+		skip: map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(logger.WithContext(r.Context(), log,
+				ctx.Str(MethodKey, r.Method),
+				ctx.Str(PathKey, r.URL.Path),
+			))
+
+			if _, ok := cfg.skip[r.URL.Path]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w}
+			next.ServeHTTP(rw, r)
+			dur := time.Since(start)
+
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			fields := make([]logger.Field, 0, 7)
+			if r.Pattern != "" {
+				fields = append(fields, ctx.Str(PatternKey, r.Pattern))
+			}
+			fields = append(fields,
+				ctx.Int(StatusKey, status),
+				ctx.Int64(SizeKey, rw.size),
+				ctx.Duration(DurationKey, dur),
+				ctx.Str(RemoteKey, r.RemoteAddr),
+				ctx.Str(UserAgentKey, r.UserAgent()),
+			)
+
+			reqLog := log.Ctx(r.Context())
+			switch {
+			case status >= http.StatusInternalServerError:
+				reqLog.Error(cfg.msg, fields...)
+			case status >= http.StatusBadRequest:
+				reqLog.Warn(cfg.msg, fields...)
+			default:
+				reqLog.Info(cfg.msg, fields...)
+			}
+		})
+	}
+}
+
+// responseWriter records the status and size of a response.
+type responseWriter struct {
+	http.ResponseWriter
+
+	status int
+	size   int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	// Informational responses are followed by the final response.
+	if w.status == 0 && (status >= http.StatusOK || status == http.StatusSwitchingProtocols) {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,170 @@
+package httplog_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/httplog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   string
+	}{
+		{
+			name:   "ok",
+			status: http.StatusOK,
+			want:   `lvl=info msg="http request" method=GET path=/items/1 pattern="GET /items/{id}" status=200 size=5 duration=[^ ]+ remote_addr=192.0.2.1:1234 user_agent=test\n`,
+		},
+		{
+			name:   "client error",
+			status: http.StatusNotFound,
+			want:   `lvl=warn msg="http request" .* status=404 .*\n`,
+		},
+		{
+			name:   "server error",
+			status: http.StatusBadGateway,
+			want:   `lvl=eror msg="http request" .* status=502 .*\n`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte("hello"))
+			})
+			h := httplog.Middleware(log)(mux)
+
+			req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+			req.Header.Set("User-Agent", "test")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Regexp(t, regexp.MustCompile("^"+test.want+"$"), buf.String())
+		})
+	}
+}
+
+func TestMiddleware_StoresRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+
+	h := httplog.Middleware(log, httplog.WithSkipPaths("/health"))(
+		http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			log.FromContext(r.Context()).Info("handling", ctx.Str("foo", "bar"))
+		}),
+	)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/health", nil))
+
+	assert.Equal(t, "lvl=info msg=handling method=POST path=/health foo=bar\n", buf.String())
+}
+
+func TestMiddleware_DefaultsStatus(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+
+	h := httplog.Middleware(log, httplog.WithMessage("request"))(
+		http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusEarlyHints)
+		}),
+	)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Regexp(t, `^lvl=info msg=request method=GET path=/ status=200 size=0 `, buf.String())
+}
+
+func TestMiddleware_SupportsResponseController(t *testing.T) {
+	log := logger.New(&bytes.Buffer{}, logger.LogfmtFormat(), logger.Info)
+
+	var err error
+	h := httplog.Middleware(log)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		err = http.NewResponseController(w).Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.NoError(t, err)
+	assert.True(t, rec.Flushed)
+}
+
+func TestMiddleware_SupportsFlusher(t *testing.T) {
+	log := logger.New(&bytes.Buffer{}, logger.LogfmtFormat(), logger.Info)
+
+	var ok bool
+	h := httplog.Middleware(log)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		var f http.Flusher
+		f, ok = w.(http.Flusher)
+		if ok {
+			f.Flush()
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.True(t, ok)
+	assert.True(t, rec.Flushed)
+}
+
+func TestMiddleware_SupportsHijacker(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+
+	h := httplog.Middleware(log)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hj, ok := w.(http.Hijacker)
+		if !assert.True(t, ok) {
+			return
+		}
+		conn, rw, err := hj.Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: close\r\n\r\n")
+		_ = rw.Flush()
+	}))
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	<-done
+
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Contains(t, buf.String(), " status=101 ")
+}
+
+func TestMiddleware_HijackNotSupported(t *testing.T) {
+	log := logger.New(&bytes.Buffer{}, logger.LogfmtFormat(), logger.Info)
+
+	var err error
+	h := httplog.Middleware(log)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _, err = w.(http.Hijacker).Hijack()
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.ErrorIs(t, err, http.ErrNotSupported)
+}
@@ -0,0 +1,140 @@
+package httplog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+)
+
+// Request ID defaults.
+const (
+	DefaultRequestIDHeader = "X-Request-ID"
+	RequestIDKey           = "req_id"
+)
+
+// maxRequestIDLen is the longest incoming request ID accepted.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDOption configures the request ID middleware and transport.
+type RequestIDOption func(*requestIDConfig)
+
+// WithHeader sets the header the request ID is read from and written to.
+func WithHeader(header string) RequestIDOption {
+	return func(c *requestIDConfig) {
+		c.header = header
+	}
+}
+
+// WithGenerator sets the function generating request IDs.
+func WithGenerator(fn func() string) RequestIDOption {
+	return func(c *requestIDConfig) {
+		c.gen = fn
+	}
+}
+
+type requestIDConfig struct {
+	header string
+	gen    func() string
+}
+
+func newRequestIDConfig(opts []RequestIDOption) requestIDConfig {
+	cfg := requestIDConfig{
+		header: DefaultRequestIDHeader,
+		gen:    newRequestID,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// RequestID returns a middleware that reads the request ID from the request
+// header, generating one when it is missing or invalid. The ID is stored in
+// the request context, attached as a field with WithContext and echoed on
+// the response.
+//
+// It should wrap the logging middleware so the ID is included in its entries.
+func RequestID(log *logger.Logger, opts ...RequestIDOption) func(http.Handler) http.Handler {
+	cfg := newRequestIDConfig(opts)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(cfg.header)
+			if !validRequestID(id) {
+				id = cfg.gen()
+			}
+
+			c := ContextWithRequestID(r.Context(), id)
+			c = logger.WithContext(c, log, ctx.Str(RequestIDKey, id))
+
+			w.Header().Set(cfg.header, id)
+			next.ServeHTTP(w, r.WithContext(c))
+		})
+	}
+}
+
+// RequestIDTransport propagates the request ID in the request context
+// onto outgoing requests.
+type RequestIDTransport struct {
+	rt     http.RoundTripper
+	header string
+}
+
+// NewRequestIDTransport returns a transport setting the request ID header
+// on requests made through rt. If rt is nil, http.DefaultTransport is used.
+func NewRequestIDTransport(rt http.RoundTripper, opts ...RequestIDOption) *RequestIDTransport {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	cfg := newRequestIDConfig(opts)
+
+	return &RequestIDTransport{
+		rt:     rt,
+		header: cfg.header,
+	}
+}
+
+// RoundTrip executes a single HTTP transaction.
+func (t *RequestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if id := RequestIDFromContext(req.Context()); id != "" && req.Header.Get(t.header) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(t.header, id)
+	}
+	return t.rt.RoundTrip(req)
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID reports whether an incoming request ID is safe to use.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := range len(id) {
+		if c := id[i]; c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package httplog_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/httplog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		id     string
+		want   string
+	}{
+		{
+			name: "uses incoming id",
+			id:   "abc-123",
+			want: "abc-123",
+		},
+		{
+			name:   "uses configured header",
+			header: "X-Correlation-ID",
+			id:     "abc-123",
+			want:   "abc-123",
+		},
+		{
+			name: "generates missing id",
+			want: "generated",
+		},
+		{
+			name: "generates invalid id",
+			id:   "abc 123",
+			want: "generated",
+		},
+		{
+			name: "generates too long id",
+			id:   strings.Repeat("a", 129),
+			want: "generated",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header := test.header
+			opts := []httplog.RequestIDOption{httplog.WithGenerator(func() string { return "generated" })}
+			if header != "" {
+				opts = append(opts, httplog.WithHeader(header))
+			} else {
+				header = httplog.DefaultRequestIDHeader
+			}
+
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+
+			var gotID string
+			h := httplog.RequestID(log, opts...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				gotID = httplog.RequestIDFromContext(r.Context())
+				log.FromContext(r.Context()).Info("handling")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.id != "" {
+				req.Header.Set(header, test.id)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.want, gotID)
+			assert.Equal(t, test.want, rec.Header().Get(header))
+			assert.Equal(t, "lvl=info msg=handling req_id="+test.want+"\n", buf.String())
+		})
+	}
+}
+
+func TestRequestID_GeneratesRandomID(t *testing.T) {
+	log := logger.New(&bytes.Buffer{}, logger.LogfmtFormat(), logger.Info)
+	h := httplog.RequestID(log)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	rec1 := httptest.NewRecorder()
+	h.ServeHTTP(rec1, httptest.NewRequest(http.MethodGet, "/", nil))
+	rec2 := httptest.NewRecorder()
+	h.ServeHTTP(rec2, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	id := rec1.Header().Get(httplog.DefaultRequestIDHeader)
+	assert.Len(t, id, 32)
+	assert.NotEqual(t, id, rec2.Header().Get(httplog.DefaultRequestIDHeader))
+}
+
+func TestRequestID_WithMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+
+	h := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	srv := httplog.RequestID(log)(httplog.Middleware(log)(h))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(httplog.DefaultRequestIDHeader, "abc-123")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Regexp(t, `^lvl=info msg="http request" req_id=abc-123 method=GET path=/ status=200 `, buf.String())
+}
+
+func TestRequestIDTransport(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("X-Correlation-ID"))
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{
+		Transport: httplog.NewRequestIDTransport(nil, httplog.WithHeader("X-Correlation-ID")),
+	}
+
+	c := httplog.ContextWithRequestID(context.Background(), "abc-123")
+	req, err := http.NewRequestWithContext(c, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, []string{"abc-123", ""}, got)
+	assert.Empty(t, req.Header.Get("X-Correlation-ID"))
+}
@@ -0,0 +1,194 @@
+package httplog
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+)
+
+// Keys of the fields logged by the transport.
+const (
+	URLKey             = "url"
+	RequestHeadersKey  = "req_headers"
+	ResponseHeadersKey = "resp_headers"
+)
+
+// DefaultRedactedParams are the query parameters redacted by default.
+var DefaultRedactedParams = []string{
+	"access_token", "api_key", "apikey", "key", "password", "secret", "sig", "signature", "token",
+}
+
+// DefaultSensitiveHeaders are the headers masked in header dumps by default.
+var DefaultSensitiveHeaders = []string{
+	"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie", "X-Api-Key",
+}
+
+// TransportOption configures the logging transport.
+type TransportOption func(*Transport)
+
+// WithRedactedParams sets the query parameters whose values are redacted
+// from logged URLs, replacing the defaults. Parameters match case-insensitively.
+func WithRedactedParams(params ...string) TransportOption {
+	return func(t *Transport) {
+		t.params = lower(params)
+	}
+}
+
+// WithHeaderDump logs the request and response headers in a separate
+// debug level entry, masking sensitive headers.
+func WithHeaderDump() TransportOption {
+	return func(t *Transport) {
+		t.dump = true
+	}
+}
+
+// WithSensitiveHeaders sets the headers masked in header dumps, replacing
+// the defaults.
+func WithSensitiveHeaders(headers ...string) TransportOption {
+	return func(t *Transport) {
+		t.sensitive = make([]string, len(headers))
+		for i, h := range headers {
+			t.sensitive[i] = http.CanonicalHeaderKey(h)
+		}
+	}
+}
+
+// WithTransportMessage sets the message requests are logged with.
+func WithTransportMessage(msg string) TransportOption {
+	return func(t *Transport) {
+		t.msg = msg
+	}
+}
+
+// Transport logs requests made through an http.RoundTripper.
+type Transport struct {
+	rt  http.RoundTripper
+	log *logger.Logger
+
+	msg       string
+	params    []string
+	sensitive []string
+	dump      bool
+}
+
+// NewTransport returns a transport logging requests made through rt with
+// the logger of the request context. If rt is nil, http.DefaultTransport
+// is used.
+//
+// Requests are logged at info level, while 5xx responses and transport
+// errors are logged at error level.
+func NewTransport(rt http.RoundTripper, log *logger.Logger, opts ...TransportOption) *Transport {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	t := &Transport{
+		rt:        rt,
+		log:       log,
+		msg:       "http client request",
+		params:    lower(DefaultRedactedParams),
+		sensitive: DefaultSensitiveHeaders,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// RoundTrip executes a single HTTP transaction.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	log := t.log.Ctx(req.Context())
+
+	start := time.Now()
+	resp, err := t.rt.RoundTrip(req)
+	dur := time.Since(start)
+
+	fields := make([]logger.Field, 0, 6)
+	fields = append(fields,
+		ctx.Str(MethodKey, req.Method),
+		ctx.Str(URLKey, t.redactURL(req.URL)),
+	)
+	if err != nil {
+		fields = append(fields, ctx.Duration(DurationKey, dur), ctx.Err(err))
+		log.Error(t.msg, fields...)
+		return resp, err
+	}
+
+	fields = append(fields, ctx.Int(StatusKey, resp.StatusCode))
+	if resp.ContentLength >= 0 {
+		fields = append(fields, ctx.Int64(SizeKey, resp.ContentLength))
+	}
+	fields = append(fields, ctx.Duration(DurationKey, dur))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		log.Error(t.msg, fields...)
+	} else {
+		log.Info(t.msg, fields...)
+	}
+
+	if t.dump && log.Enabled(logger.Debug) {
+		log.Debug(t.msg+" headers",
+			ctx.Str(MethodKey, req.Method),
+			ctx.Str(URLKey, t.redactURL(req.URL)),
+			ctx.Strs(RequestHeadersKey, t.headers(req.Header)),
+			ctx.Strs(ResponseHeadersKey, t.headers(resp.Header)),
+		)
+	}
+
+	return resp, nil
+}
+
+func (t *Transport) redactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+
+	// The raw query is rewritten in place to keep the parameter order.
+	parts := strings.Split(u.RawQuery, "&")
+	for i, part := range parts {
+		k, _, _ := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(k); err == nil && slices.Contains(t.params, strings.ToLower(name)) {
+			parts[i] = k + "=" + logger.Redacted
+		}
+	}
+
+	cpy := *u
+	cpy.RawQuery = strings.Join(parts, "&")
+	return cpy.Redacted()
+}
+
+// headers returns the headers as sorted "Name: value" lines, masking
+// sensitive headers.
+func (t *Transport) headers(h http.Header) []string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		mask := slices.Contains(t.sensitive, http.CanonicalHeaderKey(name))
+		for _, v := range h[name] {
+			if mask {
+				v = logger.Redacted
+			}
+			lines = append(lines, name+": "+v)
+		}
+	}
+	return lines
+}
+
+func lower(s []string) []string {
+	l := make([]string, len(s))
+	for i, v := range s {
+		l[i] = strings.ToLower(v)
+	}
+	return l
+}
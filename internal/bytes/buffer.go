@@ -9,7 +9,8 @@ import (
 
 // Buffer wraps a byte slice, providing continence functions.
 type Buffer struct {
-	b []byte
+	b     []byte
+	marks []int
 }
 
 // NewBuffer returns a buffer.
@@ -79,8 +80,43 @@ func (b *Buffer) Len() int {
 	return len(b.b)
 }
 
-// Reset resets the underlying byte slice. Subsequent writes re-use the slice's
-// backing array.
+// Truncate discards all but the first n bytes of the buffer, and the
+// marks past them.
+func (b *Buffer) Truncate(n int) {
+	b.b = b.b[:n]
+	for len(b.marks) > 0 && b.marks[len(b.marks)-1] >= n {
+		b.marks = b.marks[:len(b.marks)-1]
+	}
+}
+
+// PushMark records a position in the buffer, such as the start of an
+// open container.
+func (b *Buffer) PushMark(i int) {
+	b.marks = append(b.marks, i)
+}
+
+// PopMark removes and returns the last recorded position, or -1 if
+// there is none.
+func (b *Buffer) PopMark() int {
+	if len(b.marks) == 0 {
+		return -1
+	}
+	i := b.marks[len(b.marks)-1]
+	b.marks = b.marks[:len(b.marks)-1]
+	return i
+}
+
+// PeekMark returns the last recorded position, or -1 if there is none.
+func (b *Buffer) PeekMark() int {
+	if len(b.marks) == 0 {
+		return -1
+	}
+	return b.marks[len(b.marks)-1]
+}
+
+// Reset resets the underlying byte slice and marks. Subsequent writes
+// re-use the slice's backing array.
 func (b *Buffer) Reset() {
 	b.b = b.b[:0]
+	b.marks = b.marks[:0]
 }
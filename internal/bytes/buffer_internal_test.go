@@ -1,6 +1,7 @@
 package bytes
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
@@ -65,6 +66,49 @@ func TestBuffer(t *testing.T) {
 			fn:   func() { buf.AppendTime(time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC), time.DateTime) },
 			want: "2026-01-02 15:04:05",
 		},
+		{
+			name: "Truncate",
+			fn: func() {
+				buf.WriteString("foobar")
+				buf.Truncate(3)
+			},
+			want: "foo",
+		},
+		{
+			name: "PopMark",
+			fn: func() {
+				buf.WriteString("foo")
+				buf.PushMark(1)
+				buf.PushMark(2)
+				buf.WriteString(strconv.Itoa(buf.PopMark()))
+				buf.WriteString(strconv.Itoa(buf.PopMark()))
+				buf.WriteString(strconv.Itoa(buf.PopMark()))
+			},
+			want: "foo21-1",
+		},
+		{
+			name: "PeekMark",
+			fn: func() {
+				buf.WriteString("foo")
+				buf.WriteString(strconv.Itoa(buf.PeekMark()))
+				buf.PushMark(1)
+				buf.WriteString(strconv.Itoa(buf.PeekMark()))
+				buf.WriteString(strconv.Itoa(buf.PeekMark()))
+			},
+			want: "foo-111",
+		},
+		{
+			name: "TruncateMarks",
+			fn: func() {
+				buf.WriteString("foobar")
+				buf.PushMark(1)
+				buf.PushMark(4)
+				buf.Truncate(3)
+				buf.WriteString(strconv.Itoa(buf.PopMark()))
+				buf.WriteString(strconv.Itoa(buf.PopMark()))
+			},
+			want: "foo1-1",
+		},
 		{
 			name: "AppendDuration",
 			fn:   func() { buf.AppendDuration(3*time.Hour + 2*time.Minute + time.Second) },
@@ -0,0 +1,48 @@
+// Package otelconv converts decoded record fields into OpenTelemetry attributes.
+package otelconv
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/hamba/logger/v2/internal/record"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Attribute converts a field into an attribute.
+func Attribute(f record.Field) attribute.KeyValue {
+	return attribute.KeyValue{Key: attribute.Key(f.Key), Value: Value(f.Value)}
+}
+
+// Value converts a field value into an attribute value, following
+// the conventions of the log/slog bridge.
+func Value(v any) attribute.Value {
+	switch v := v.(type) {
+	case string:
+		return attribute.StringValue(v)
+	case bool:
+		return attribute.BoolValue(v)
+	case int64:
+		return attribute.Int64Value(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return attribute.StringValue(strconv.FormatUint(v, 10))
+		}
+		return attribute.Int64Value(int64(v))
+	case float64:
+		return attribute.Float64Value(v)
+	case time.Time:
+		return attribute.Int64Value(v.UnixNano())
+	case time.Duration:
+		return attribute.Int64Value(v.Nanoseconds())
+	case []any:
+		vals := make([]attribute.Value, len(v))
+		for i, vv := range v {
+			vals[i] = Value(vv)
+		}
+		return attribute.SliceValue(vals...)
+	default:
+		return attribute.Value{}
+	}
+}
@@ -0,0 +1,282 @@
+// Package record implements a typed binary encoding of log entries.
+//
+// The encoding is used to pass entries in structured form from a Logger
+// to writers that need the typed fields rather than formatted text.
+// Each write from the logger contains a single entry.
+package record
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/internal/bytes"
+)
+
+const (
+	tagMessage byte = iota + 1
+	tagKey
+	tagString
+	tagBool
+	tagInt
+	tagUint
+	tagFloat
+	tagTime
+	tagDuration
+	tagNil
+	tagArrayStart
+	tagArrayEnd
+)
+
+var errShort = errors.New("record: unexpected end of entry")
+
+// Field is a decoded context field.
+//
+// The value is one of string, bool, int64, uint64, float64, time.Time,
+// time.Duration, []any or nil.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Entry is a decoded log entry.
+type Entry struct {
+	Time   time.Time
+	Level  logger.Level
+	Msg    string
+	Fields []Field
+}
+
+type format struct{}
+
+// Format returns a formatter that encodes entries in the record encoding.
+func Format() logger.Formatter {
+	return &format{}
+}
+
+func (f *format) WriteMessage(buf *bytes.Buffer, ts time.Time, lvl logger.Level, msg string) {
+	buf.WriteByte(tagMessage)
+	if ts.IsZero() {
+		buf.WriteByte(0)
+	} else {
+		buf.WriteByte(1)
+		appendVarint(buf, ts.UnixNano())
+	}
+	appendVarint(buf, int64(lvl))
+	appendString(buf, msg)
+}
+
+func (f *format) AppendBeginMarker(*bytes.Buffer) {}
+
+func (f *format) AppendEndMarker(*bytes.Buffer) {}
+
+func (f *format) AppendLineBreak(*bytes.Buffer) {}
+
+func (f *format) AppendArrayStart(buf *bytes.Buffer) {
+	buf.WriteByte(tagArrayStart)
+}
+
+func (f *format) AppendArraySep(*bytes.Buffer) {}
+
+func (f *format) AppendArrayEnd(buf *bytes.Buffer) {
+	buf.WriteByte(tagArrayEnd)
+}
+
+func (f *format) AppendKey(buf *bytes.Buffer, key string) {
+	buf.WriteByte(tagKey)
+	appendString(buf, key)
+}
+
+func (f *format) AppendString(buf *bytes.Buffer, s string) {
+	buf.WriteByte(tagString)
+	appendString(buf, s)
+}
+
+func (f *format) AppendBool(buf *bytes.Buffer, b bool) {
+	buf.WriteByte(tagBool)
+	if b {
+		buf.WriteByte(1)
+		return
+	}
+	buf.WriteByte(0)
+}
+
+func (f *format) AppendInt(buf *bytes.Buffer, i int64) {
+	buf.WriteByte(tagInt)
+	appendVarint(buf, i)
+}
+
+func (f *format) AppendUint(buf *bytes.Buffer, i uint64) {
+	buf.WriteByte(tagUint)
+	appendUvarint(buf, i)
+}
+
+func (f *format) AppendFloat(buf *bytes.Buffer, fl float64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(fl))
+
+	buf.WriteByte(tagFloat)
+	buf.Write(b[:])
+}
+
+func (f *format) AppendTime(buf *bytes.Buffer, t time.Time) {
+	buf.WriteByte(tagTime)
+	appendVarint(buf, t.UnixNano())
+}
+
+func (f *format) AppendDuration(buf *bytes.Buffer, d time.Duration) {
+	buf.WriteByte(tagDuration)
+	appendVarint(buf, int64(d))
+}
+
+func (f *format) AppendInterface(buf *bytes.Buffer, v any) {
+	if v == nil {
+		buf.WriteByte(tagNil)
+		return
+	}
+
+	f.AppendString(buf, fmt.Sprintf("%+v", v))
+}
+
+func appendString(buf *bytes.Buffer, s string) {
+	appendUvarint(buf, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+func appendVarint(buf *bytes.Buffer, i int64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(b[:], i)
+	buf.Write(b[:n])
+}
+
+func appendUvarint(buf *bytes.Buffer, i uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], i)
+	buf.Write(b[:n])
+}
+
+// Decode decodes an entry written with the record formatter.
+func Decode(p []byte) (Entry, error) {
+	d := decoder{b: p}
+
+	var e Entry
+	if len(p) > 0 && p[0] == tagMessage {
+		d.i++
+		if d.byte() == 1 {
+			e.Time = time.Unix(0, d.varint())
+		}
+		e.Level = logger.Level(d.varint())
+		e.Msg = d.string()
+	}
+
+	for d.err == nil && d.i < len(d.b) {
+		if d.byte() != tagKey {
+			return Entry{}, fmt.Errorf("record: expected key at offset %d", d.i-1)
+		}
+		key := d.string()
+		val := d.value()
+		e.Fields = append(e.Fields, Field{Key: key, Value: val})
+	}
+	if d.err != nil {
+		return Entry{}, d.err
+	}
+	return e, nil
+}
+
+type decoder struct {
+	b   []byte
+	i   int
+	err error
+}
+
+func (d *decoder) byte() byte {
+	if d.i >= len(d.b) {
+		d.fail()
+		return 0
+	}
+	b := d.b[d.i]
+	d.i++
+	return b
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.b[min(d.i, len(d.b)):])
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.i += n
+	return v
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.b[min(d.i, len(d.b)):])
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.i += n
+	return v
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if n > uint64(len(d.b)-d.i) {
+		d.fail()
+		return ""
+	}
+	s := string(d.b[d.i : d.i+int(n)])
+	d.i += int(n)
+	return s
+}
+
+func (d *decoder) value() any {
+	switch tag := d.byte(); tag {
+	case tagString:
+		return d.string()
+	case tagBool:
+		return d.byte() == 1
+	case tagInt:
+		return d.varint()
+	case tagUint:
+		return d.uvarint()
+	case tagFloat:
+		if len(d.b)-d.i < 8 {
+			d.fail()
+			return nil
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(d.b[d.i:]))
+		d.i += 8
+		return f
+	case tagTime:
+		return time.Unix(0, d.varint())
+	case tagDuration:
+		return time.Duration(d.varint())
+	case tagNil:
+		return nil
+	case tagArrayStart:
+		arr := []any{}
+		for d.err == nil {
+			if d.i < len(d.b) && d.b[d.i] == tagArrayEnd {
+				d.i++
+				return arr
+			}
+			arr = append(arr, d.value())
+		}
+		return nil
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("record: unknown tag %d at offset %d", tag, d.i-1)
+		}
+		return nil
+	}
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = errShort
+	}
+}
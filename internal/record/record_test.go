@@ -0,0 +1,106 @@
+package record_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/internal/record"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, record.Format(), logger.Info).With(ctx.Str("svc", "api"))
+
+	log.Warn("some message",
+		ctx.Str("str", "string"),
+		ctx.Strs("strs", []string{"a", "b"}),
+		ctx.Bytes("bytes", []byte{1, 2}),
+		ctx.Bool("bool", true),
+		ctx.Int("int", -1),
+		ctx.Uint("uint", 2),
+		ctx.Float64("float", 4.56),
+		ctx.Time("time", time.Unix(123, 456)),
+		ctx.Duration("dur", time.Second),
+		ctx.Interface("obj", struct{ Name string }{Name: "test"}),
+		ctx.Interface("nil", nil),
+	)
+
+	got, err := record.Decode(buf.Bytes())
+
+	require.NoError(t, err)
+	want := record.Entry{
+		Level: logger.Warn,
+		Msg:   "some message",
+		Fields: []record.Field{
+			{Key: "svc", Value: "api"},
+			{Key: "str", Value: "string"},
+			{Key: "strs", Value: []any{"a", "b"}},
+			{Key: "bytes", Value: []any{int64(1), int64(2)}},
+			{Key: "bool", Value: true},
+			{Key: "int", Value: int64(-1)},
+			{Key: "uint", Value: uint64(2)},
+			{Key: "float", Value: 4.56},
+			{Key: "time", Value: time.Unix(123, 456)},
+			{Key: "dur", Value: time.Second},
+			{Key: "obj", Value: "{Name:test}"},
+			{Key: "nil", Value: nil},
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestDecode_Timestamp(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, record.Format(), logger.Info)
+	cancel := log.WithTimestamp()
+	t.Cleanup(cancel)
+
+	log.Info("some message")
+
+	got, err := record.Decode(buf.Bytes())
+
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), got.Time, time.Second)
+}
+
+func TestDecode_HandlesInvalidEntries(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, record.Format(), logger.Info)
+	log.Info("some message", ctx.Str("str", "string"), ctx.Float64("float", 4.56))
+	p := buf.Bytes()
+
+	tests := []struct {
+		name string
+		in   []byte
+	}{
+		{
+			name: "text",
+			in:   []byte("lvl=info msg=test"),
+		},
+		{
+			name: "truncated string",
+			in:   p[:len(p)-14],
+		},
+		{
+			name: "truncated float",
+			in:   p[:len(p)-4],
+		},
+		{
+			name: "unknown tag",
+			in:   append(p[:len(p):len(p)], 2, 1, 'k', 99),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := record.Decode(test.in)
+
+			assert.Error(t, err)
+		})
+	}
+}
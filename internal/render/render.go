@@ -0,0 +1,53 @@
+// Package render implements rendering parsed records with a formatter.
+package render
+
+import (
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/internal/bytes"
+	"github.com/hamba/logger/v2/parser"
+)
+
+// Append appends the record to the buffer as the logger would write it
+// with the formatter.
+func Append(buf *bytes.Buffer, fmtr logger.Formatter, rec parser.Record) {
+	fmtr.AppendBeginMarker(buf)
+	fmtr.WriteMessage(buf, rec.Time, rec.Level, rec.Msg)
+	for _, f := range rec.Fields {
+		fmtr.AppendKey(buf, f.Key)
+		appendValue(buf, fmtr, f.Value)
+	}
+	fmtr.AppendEndMarker(buf)
+	fmtr.AppendLineBreak(buf)
+}
+
+func appendValue(buf *bytes.Buffer, fmtr logger.Formatter, v any) {
+	switch v := v.(type) {
+	case string:
+		fmtr.AppendString(buf, v)
+	case bool:
+		fmtr.AppendBool(buf, v)
+	case int64:
+		fmtr.AppendInt(buf, v)
+	case uint64:
+		fmtr.AppendUint(buf, v)
+	case float64:
+		fmtr.AppendFloat(buf, v)
+	case time.Time:
+		fmtr.AppendTime(buf, v)
+	case time.Duration:
+		fmtr.AppendDuration(buf, v)
+	case []any:
+		fmtr.AppendArrayStart(buf)
+		for i, e := range v {
+			if i > 0 {
+				fmtr.AppendArraySep(buf)
+			}
+			appendValue(buf, fmtr, e)
+		}
+		fmtr.AppendArrayEnd(buf)
+	default:
+		fmtr.AppendInterface(buf, v)
+	}
+}
@@ -0,0 +1,66 @@
+package render_test
+
+import (
+	stdbytes "bytes"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/internal/bytes"
+	"github.com/hamba/logger/v2/internal/render"
+	"github.com/hamba/logger/v2/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppend(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		fmtr func() logger.Formatter
+	}{
+		{
+			name: "json",
+			fmtr: func() logger.Formatter { return logger.JSONFormat() },
+		},
+		{
+			name: "logfmt",
+			fmtr: func() logger.Formatter { return logger.LogfmtFormat() },
+		},
+		{
+			name: "console",
+			fmtr: func() logger.Formatter { return logger.ConsoleFormat() },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var want stdbytes.Buffer
+			log := logger.New(&want, test.fmtr(), logger.Info)
+			log.Error("some message",
+				ctx.Str("str", "some \"string\""),
+				ctx.Strs("strs", []string{"a", "b"}),
+				ctx.Bool("bool", true),
+				ctx.Int("int", -1),
+				ctx.Uint("uint", 2),
+				ctx.Float64("float", 4.5),
+				ctx.Duration("dur", time.Second),
+				ctx.Interface("nil", nil),
+			)
+
+			line := `{"lvl":"eror","msg":"some message","str":"some \"string\"","strs":["a","b"],` +
+				`"bool":true,"int":-1,"uint":2,"float":4.5,"dur":"1s","nil":null}`
+			rec, err := parser.Parse([]byte(line))
+			require.NoError(t, err)
+
+			buf := bytes.NewBuffer(512)
+			render.Append(buf, test.fmtr(), rec)
+
+			assert.Equal(t, want.String(), string(buf.Bytes()))
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package logger
+
+import (
+	stdbytes "bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hamba/logger/v2/internal/bytes"
+)
+
+// Journald field names used by the journald formatter.
+const (
+	journaldPriorityKey = "PRIORITY"
+	journaldMessageKey  = "MESSAGE"
+	journaldFileKey     = "CODE_FILE"
+	journaldLineKey     = "CODE_LINE"
+)
+
+// syslogSeverity returns the syslog severity of a level.
+func syslogSeverity(lvl Level) int {
+	switch lvl {
+	case Crit:
+		return 2
+	case Error:
+		return 3
+	case Warn:
+		return 4
+	case Info:
+		return 6
+	default:
+		return 7
+	}
+}
+
+type journald struct{}
+
+// JournaldFormat formats a log line in the systemd-journald native protocol.
+//
+// The level is mapped to the journal PRIORITY and the message is sent as
+// MESSAGE. Context keys are upper-cased and any characters not allowed in
+// journal field names are replaced with underscores, keys colliding with
+// MESSAGE or PRIORITY are prefixed with FIELD_. A caller logged under the
+// key "code_file" is split into CODE_FILE and CODE_LINE. Values spanning
+// multiple lines are sent with the binary-safe framing.
+//
+// The timestamp is not written, journald stamps each entry on receipt.
+func JournaldFormat() Formatter {
+	return &journald{}
+}
+
+func (j *journald) WriteMessage(buf *bytes.Buffer, _ time.Time, lvl Level, msg string) {
+	buf.WriteString(journaldPriorityKey + "=")
+	buf.AppendInt(int64(syslogSeverity(lvl)))
+	buf.WriteString("\n" + journaldMessageKey + "=")
+	appendJournaldValue(buf, msg)
+}
+
+func (j *journald) AppendBeginMarker(*bytes.Buffer) {}
+
+func (j *journald) AppendEndMarker(*bytes.Buffer) {}
+
+func (j *journald) AppendLineBreak(buf *bytes.Buffer) {
+	buf.WriteByte('\n')
+}
+
+// AppendArrayStart appends a placeholder size for the array of a field,
+// framed when the array ends if any element spans multiple lines. The
+// placeholder is marked in the buffer, an array nested in another marking
+// the placeholder of the array holding it.
+func (j *journald) AppendArrayStart(buf *bytes.Buffer) {
+	if i := buf.PeekMark(); i >= 0 {
+		buf.PushMark(i)
+		return
+	}
+
+	// Replace the separator of the field with a line break.
+	b := buf.Bytes()
+	b[len(b)-1] = '\n'
+	buf.PushMark(buf.Len())
+	buf.Write(make([]byte, 8))
+}
+
+func (j *journald) AppendArraySep(buf *bytes.Buffer) {
+	buf.WriteByte(',')
+}
+
+func (j *journald) AppendArrayEnd(buf *bytes.Buffer) {
+	start := buf.PopMark()
+	if start < 0 || buf.PeekMark() == start {
+		// Nested arrays are written as part of the array holding them.
+		return
+	}
+
+	b := buf.Bytes()
+	val := start + 8
+	if stdbytes.IndexByte(b[val:], '\n') >= 0 {
+		binary.LittleEndian.PutUint64(b[start:val], uint64(len(b)-val))
+		return
+	}
+
+	// Write arrays on a single line unless they must be framed.
+	b[start-1] = '='
+	copy(b[start:], b[val:])
+	buf.Truncate(len(b) - 8)
+}
+
+func (j *journald) AppendKey(buf *bytes.Buffer, key string) {
+	buf.WriteByte('\n')
+	start := buf.Len()
+
+	// Journal field names may only contain upper case letters, digits
+	// and underscores, must start with a letter and are at most 64 long.
+	n := 0
+	for i := 0; i < len(key) && n < 64; i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z':
+		case n == 0:
+			continue
+		case c >= '0' && c <= '9':
+		default:
+			c = '_'
+		}
+		buf.WriteByte(c)
+		n++
+	}
+
+	switch name := string(buf.Bytes()[start:]); name {
+	case "":
+		buf.WriteString("FIELD")
+	case journaldMessageKey, journaldPriorityKey:
+		buf.Truncate(start)
+		buf.WriteString("FIELD_" + name)
+	}
+
+	buf.WriteByte('=')
+}
+
+func (j *journald) AppendString(buf *bytes.Buffer, s string) {
+	if buf.PeekMark() >= 0 {
+		// Array elements are written as is, the array being framed when
+		// it ends if needed.
+		if !utf8.ValidString(s) {
+			s = strings.ToValidUTF8(s, string(utf8.RuneError))
+		}
+		buf.WriteString(s)
+		return
+	}
+
+	if hasSuffix(buf.Bytes(), "\n"+journaldFileKey+"=") {
+		if i := strings.LastIndexByte(s, ':'); i > 0 && isDigits(s[i+1:]) {
+			appendJournaldValue(buf, s[:i])
+			buf.WriteString("\n" + journaldLineKey + "=")
+			buf.WriteString(s[i+1:])
+			return
+		}
+	}
+
+	appendJournaldValue(buf, s)
+}
+
+func (j *journald) AppendBool(buf *bytes.Buffer, b bool) {
+	buf.AppendBool(b)
+}
+
+func (j *journald) AppendInt(buf *bytes.Buffer, i int64) {
+	buf.AppendInt(i)
+}
+
+func (j *journald) AppendUint(buf *bytes.Buffer, i uint64) {
+	buf.AppendUint(i)
+}
+
+func (j *journald) AppendFloat(buf *bytes.Buffer, f float64) {
+	buf.AppendFloat(f, 'g', -1, 64)
+}
+
+func (j *journald) AppendTime(buf *bytes.Buffer, t time.Time) {
+	switch TimeFormat {
+	case TimeFormatUnix:
+		buf.AppendInt(t.Unix())
+	default:
+		buf.AppendTime(t, TimeFormat)
+	}
+}
+
+func (j *journald) AppendDuration(buf *bytes.Buffer, d time.Duration) {
+	buf.AppendDuration(d)
+}
+
+func (j *journald) AppendInterface(buf *bytes.Buffer, v any) {
+	if v == nil {
+		return
+	}
+
+	j.AppendString(buf, fmt.Sprintf("%+v", v))
+}
+
+// appendJournaldValue appends the value of the field ending the buffer,
+// using the binary-safe framing if it spans multiple lines.
+func appendJournaldValue(buf *bytes.Buffer, s string) {
+	if strings.IndexByte(s, '\n') < 0 {
+		buf.WriteString(s)
+		return
+	}
+
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(s)))
+
+	// Replace the separator of the field with a line break.
+	buf.Truncate(buf.Len() - 1)
+	buf.WriteByte('\n')
+	buf.Write(size[:])
+	buf.WriteString(s)
+}
@@ -0,0 +1,92 @@
+//go:build linux
+
+// Package journald implements a writer for the systemd-journald native protocol.
+//
+// The writer is intended to be used with logger.JournaldFormat:
+//
+//	w, err := journald.NewWriter(journald.DefaultSocket)
+//	if err != nil {
+//		// Handle error.
+//	}
+//	log := logger.New(w, logger.JournaldFormat(), logger.Info)
+package journald
+
+import (
+	"errors"
+	"net"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// DefaultSocket is the path of the journald native protocol socket.
+const DefaultSocket = "/run/systemd/journal/socket"
+
+// Writer writes log entries to journald.
+//
+// Each write is sent as a single datagram. Entries too large to be
+// sent as a datagram are written to a sealed memory file, the
+// descriptor of which is passed to journald instead.
+type Writer struct {
+	conn *net.UnixConn
+	addr *net.UnixAddr
+}
+
+// NewWriter returns a writer that writes to the journald socket at addr.
+func NewWriter(addr string) (*Writer, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		conn: conn,
+		addr: &net.UnixAddr{Name: addr, Net: "unixgram"},
+	}, nil
+}
+
+// Write writes a log entry to journald.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	_, err = w.conn.WriteToUnix(p, w.addr)
+	if err == nil {
+		return len(p), nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return 0, err
+	}
+
+	if err = w.writeFile(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *Writer) writeFile(p []byte) error {
+	fd, err := unix.MemfdCreate("logger-journald", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = unix.Close(fd) }()
+
+	for b := p; len(b) > 0; {
+		n, err := unix.Write(fd, b)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+
+	// Journald only accepts sealed memory files.
+	seals := unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE | unix.F_SEAL_SEAL
+	if _, err = unix.FcntlInt(uintptr(fd), unix.F_ADD_SEALS, seals); err != nil {
+		return err
+	}
+
+	_, _, err = w.conn.WriteMsgUnix(nil, unix.UnixRights(fd), w.addr)
+	return err
+}
+
+// Close closes the writer.
+func (w *Writer) Close() error {
+	return w.conn.Close()
+}
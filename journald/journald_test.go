@@ -0,0 +1,92 @@
+//go:build linux
+
+package journald_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/journald"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestWriter(t *testing.T) {
+	conn := listen(t)
+
+	w, err := journald.NewWriter(conn.LocalAddr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	log := logger.New(w, logger.JournaldFormat(), logger.Info)
+
+	log.Warn("some message", ctx.Str("user", "bob"))
+
+	p := make([]byte, 1024)
+	n, err := conn.Read(p)
+	require.NoError(t, err)
+	assert.Equal(t, "PRIORITY=4\nMESSAGE=some message\nUSER=bob\n", string(p[:n]))
+}
+
+func TestWriter_LargeEntry(t *testing.T) {
+	conn := listen(t)
+
+	w, err := journald.NewWriter(conn.LocalAddr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	log := logger.New(w, logger.JournaldFormat(), logger.Info)
+
+	large := strings.Repeat("a", 4<<20)
+	log.Info("some message", ctx.Str("large", large))
+
+	p := make([]byte, 1024)
+	oob := make([]byte, unix.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(p, oob)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	fds, err := unix.ParseUnixRights(&msgs[0])
+	require.NoError(t, err)
+	require.Len(t, fds, 1)
+
+	f := os.NewFile(uintptr(fds[0]), "memfd")
+	t.Cleanup(func() { _ = f.Close() })
+
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err)
+	b := make([]byte, len(large)+64)
+	n, err = f.Read(b)
+	require.NoError(t, err)
+	assert.Equal(t, "PRIORITY=6\nMESSAGE=some message\nLARGE="+large+"\n", string(b[:n]))
+}
+
+func TestWriter_HandlesMissingSocket(t *testing.T) {
+	w, err := journald.NewWriter(filepath.Join(t.TempDir(), "missing.sock"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+
+	_, err = w.Write([]byte("MESSAGE=test\n"))
+
+	assert.Error(t, err)
+}
+
+func listen(t *testing.T) *net.UnixConn {
+	t.Helper()
+
+	addr := &net.UnixAddr{Name: filepath.Join(t.TempDir(), "journal.sock"), Net: "unixgram"}
+	conn, err := net.ListenUnixgram("unixgram", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
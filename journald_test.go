@@ -0,0 +1,275 @@
+package logger_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/internal/bytes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournaldFormat(t *testing.T) {
+	fmtr := logger.JournaldFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendBeginMarker(buf)
+	fmtr.WriteMessage(buf, time.Unix(123, 0).UTC(), logger.Error, "some message")
+	fmtr.AppendKey(buf, "error")
+	fmtr.AppendString(buf, "some error")
+	fmtr.AppendEndMarker(buf)
+	fmtr.AppendLineBreak(buf)
+
+	want := "PRIORITY=3\nMESSAGE=some message\nERROR=some error\n"
+	assert.Equal(t, want, string(buf.Bytes()))
+}
+
+func TestJournaldFormat_Priorities(t *testing.T) {
+	tests := []struct {
+		lvl  logger.Level
+		want string
+	}{
+		{lvl: logger.Crit, want: "PRIORITY=2\n"},
+		{lvl: logger.Error, want: "PRIORITY=3\n"},
+		{lvl: logger.Warn, want: "PRIORITY=4\n"},
+		{lvl: logger.Info, want: "PRIORITY=6\n"},
+		{lvl: logger.Debug, want: "PRIORITY=7\n"},
+		{lvl: logger.Trace, want: "PRIORITY=7\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.lvl.String(), func(t *testing.T) {
+			fmtr := logger.JournaldFormat()
+
+			buf := bytes.NewBuffer(512)
+			fmtr.WriteMessage(buf, time.Time{}, test.lvl, "")
+
+			assert.Equal(t, test.want+"MESSAGE=", string(buf.Bytes()))
+		})
+	}
+}
+
+func TestJournaldFormat_MultilineMessage(t *testing.T) {
+	fmtr := logger.JournaldFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.WriteMessage(buf, time.Time{}, logger.Info, "foo\nbar")
+	fmtr.AppendLineBreak(buf)
+
+	want := "PRIORITY=6\nMESSAGE\n\x07\x00\x00\x00\x00\x00\x00\x00foo\nbar\n"
+	assert.Equal(t, want, string(buf.Bytes()))
+}
+
+func TestJournaldFormat_Keys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "lower case",
+			in:   "user",
+			want: "\nUSER=",
+		},
+		{
+			name: "invalid chars",
+			in:   "http.status-code",
+			want: "\nHTTP_STATUS_CODE=",
+		},
+		{
+			name: "leading underscore",
+			in:   "_n",
+			want: "\nN=",
+		},
+		{
+			name: "leading digit",
+			in:   "1st",
+			want: "\nST=",
+		},
+		{
+			name: "message",
+			in:   "message",
+			want: "\nFIELD_MESSAGE=",
+		},
+		{
+			name: "priority",
+			in:   "Priority",
+			want: "\nFIELD_PRIORITY=",
+		},
+		{
+			name: "no valid chars",
+			in:   "_",
+			want: "\nFIELD=",
+		},
+		{
+			name: "too long",
+			in:   "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz",
+			want: "\nABCDEFGHIJKLMNOPQRSTUVWXYZABCDEFGHIJKLMNOPQRSTUVWXYZABCDEFGHIJKL=",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fmtr := logger.JournaldFormat()
+
+			buf := bytes.NewBuffer(512)
+			fmtr.AppendKey(buf, test.in)
+
+			assert.Equal(t, test.want, string(buf.Bytes()))
+		})
+	}
+}
+
+func TestJournaldFormat_Strings(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "equals",
+			in:   "=",
+			want: "\nK==",
+		},
+		{
+			name: "quote",
+			in:   "\"",
+			want: "\nK=\"",
+		},
+		{
+			name: "newline",
+			in:   "foo\nbar\nbaz",
+			want: "\nK\n\x0b\x00\x00\x00\x00\x00\x00\x00foo\nbar\nbaz",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fmtr := logger.JournaldFormat()
+
+			buf := bytes.NewBuffer(512)
+			fmtr.AppendKey(buf, "k")
+			fmtr.AppendString(buf, test.in)
+
+			assert.Equal(t, test.want, string(buf.Bytes()))
+		})
+	}
+}
+
+func TestJournaldFormat_Arrays(t *testing.T) {
+	tests := []struct {
+		name  string
+		field logger.Field
+		want  string
+	}{
+		{
+			name:  "single line",
+			field: ctx.Strs("k", []string{"a", "b"}),
+			want:  "K=a,b",
+		},
+		{
+			name:  "multiple lines",
+			field: ctx.Strs("k", []string{"a\nb", "c"}),
+			want:  "K\n\x05\x00\x00\x00\x00\x00\x00\x00a\nb,c",
+		},
+		{
+			name:  "empty",
+			field: ctx.Strs("k", []string{}),
+			want:  "K=",
+		},
+		{
+			name:  "invalid utf8",
+			field: ctx.Strs("k", []string{"a\xff"}),
+			want:  "K=a\uFFFD",
+		},
+		{
+			name:  "elements ending in separator",
+			field: ctx.Strs("k", []string{"a=", "b\nc="}),
+			want:  "K\n\x07\x00\x00\x00\x00\x00\x00\x00a=,b\nc=",
+		},
+		{
+			name:  "code file elements",
+			field: ctx.Strs("code_file", []string{"/src/main.go:42"}),
+			want:  "CODE_FILE=/src/main.go:42",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sb strings.Builder
+			log := logger.New(&sb, logger.JournaldFormat(), logger.Info)
+
+			log.Info("msg", test.field, ctx.Int("after", 1))
+
+			assert.Equal(t, "PRIORITY=6\nMESSAGE=msg\n"+test.want+"\nAFTER=1\n", sb.String())
+		})
+	}
+}
+
+func TestJournaldFormat_NestedArrays(t *testing.T) {
+	fmtr := logger.JournaldFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendKey(buf, "k")
+	fmtr.AppendArrayStart(buf)
+	fmtr.AppendArrayStart(buf)
+	fmtr.AppendString(buf, "a\nb")
+	fmtr.AppendArrayEnd(buf)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendInt(buf, 1)
+	fmtr.AppendArrayEnd(buf)
+
+	assert.Equal(t, "\nK\n\x05\x00\x00\x00\x00\x00\x00\x00a\nb,1", string(buf.Bytes()))
+}
+
+func TestJournaldFormat_NestedArraysAfterSeparator(t *testing.T) {
+	fmtr := logger.JournaldFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendKey(buf, "k")
+	fmtr.AppendArrayStart(buf)
+	fmtr.AppendString(buf, "a=")
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendArrayStart(buf)
+	fmtr.AppendString(buf, "b")
+	fmtr.AppendArrayEnd(buf)
+	fmtr.AppendArrayEnd(buf)
+	fmtr.AppendKey(buf, "after")
+	fmtr.AppendString(buf, "c")
+
+	assert.Equal(t, "\nK=a=,b\nAFTER=c", string(buf.Bytes()))
+}
+
+func TestJournaldFormat_CodeFile(t *testing.T) {
+	fmtr := logger.JournaldFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendKey(buf, "code_file")
+	fmtr.AppendString(buf, "/src/main.go:42")
+
+	assert.Equal(t, "\nCODE_FILE=/src/main.go\nCODE_LINE=42", string(buf.Bytes()))
+}
+
+func TestJournaldFormat_Values(t *testing.T) {
+	fmtr := logger.JournaldFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendBool(buf, true)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendInt(buf, -5)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendUint(buf, 5)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendFloat(buf, 4.56)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendTime(buf, time.Unix(1541573670, 0).UTC())
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendDuration(buf, time.Second)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendInterface(buf, struct{ Name string }{Name: "test"})
+	fmtr.AppendInterface(buf, nil)
+
+	assert.Equal(t, "true,-5,5,4.56,1541573670,1s,{Name:test}", string(buf.Bytes()))
+}
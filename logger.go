@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"slices"
 	"sync/atomic"
 	"time"
 )
@@ -24,6 +25,12 @@ const (
 	TimeFormatISO8601 = "2006-01-02T15:04:05-0700"
 )
 
+// Fixed precision RFC3339 time formats used by the structured formatters.
+const (
+	timeFormatMilli = "2006-01-02T15:04:05.000Z07:00"
+	timeFormatNano  = "2006-01-02T15:04:05.000000000Z07:00"
+)
+
 // List of predefined log Levels.
 const (
 	Disabled Level = iota
@@ -88,7 +95,17 @@ type Logger struct {
 	fmtr      Formatter
 	timeFn    func() time.Time
 	ctx       []byte
+	ctxKeys   []fieldSpan
+	fields    []recordedField
 	lvl       Level
+	traceKeys TraceKeys
+
+	redact   *Redactor
+	dups     DuplicatePolicy
+	hook     Hook
+	hookCtx  []byte
+	hookKeys []fieldSpan
+	goCtx    context.Context
 }
 
 // New creates a new Logger.
@@ -100,6 +117,7 @@ func New(w io.Writer, fmtr Formatter, lvl Level) *Logger {
 		isDiscard: isDiscard,
 		fmtr:      fmtr,
 		lvl:       lvl,
+		traceKeys: DefaultTraceKeys,
 	}
 }
 
@@ -140,27 +158,51 @@ func (l *Logger) WithTimestamp() (cancel func()) {
 }
 
 // With returns a new Logger with the given context.
+//
+// The fields are evaluated once, their values being kept to render them
+// again for a hook, redactor or duplicate policy set later.
 func (l *Logger) With(ctx ...Field) *Logger {
-	e := newEvent(l.fmtr)
+	fields := recordFields(ctx)
+
+	log := l.withCtx(l.render(l.fmtr, l.ctx, l.ctxKeys, fields))
+	log.fields = slices.Concat(l.fields, fields)
+	if l.hook != nil {
+		log.hookCtx, log.hookKeys = l.render(l.hook.Formatter(), l.hookCtx, l.hookKeys, fields)
+	}
+	return log
+}
+
+// render renders the recorded fields after the pre-rendered context into
+// a new byte slice.
+func (l *Logger) render(fmtr Formatter, ctx []byte, keys []fieldSpan, fields []recordedField) ([]byte, []fieldSpan) {
+	e := l.newEvent(fmtr)
 	defer putEvent(e)
 
-	e.buf.Write(l.ctx)
+	e.writeCtx(ctx, keys)
+	e.replay(fields)
 
-	for _, field := range ctx {
-		field(e)
+	return e.rendered()
+}
+
+// rerender renders the fields of the logger again, for the formatter and
+// the hook formatter, if any.
+func (l *Logger) rerender() {
+	l.ctx, l.ctxKeys = l.render(l.fmtr, nil, nil, l.fields)
+	if l.hook != nil {
+		l.hookCtx, l.hookKeys = l.render(l.hook.Formatter(), nil, nil, l.fields)
 	}
+}
 
-	b := make([]byte, e.buf.Len())
-	copy(b, e.buf.Bytes())
+func (l *Logger) newEvent(fmtr Formatter) *Event {
+	return newEvent(fmtr, l.redact, l.dups)
+}
 
-	return &Logger{
-		w:         l.w,
-		isDiscard: l.isDiscard,
-		fmtr:      l.fmtr,
-		timeFn:    l.timeFn,
-		lvl:       l.lvl,
-		ctx:       b,
-	}
+// withCtx returns a copy of the Logger with the given pre-rendered context.
+func (l *Logger) withCtx(ctx []byte, keys []fieldSpan) *Logger {
+	log := *l
+	log.ctx = ctx
+	log.ctxKeys = keys
+	return &log
 }
 
 // Trace logs a trace message, intended for fine grained debug messages.
@@ -193,6 +235,12 @@ func (l *Logger) Crit(msg string, ctx ...Field) {
 	l.write(msg, Crit, ctx)
 }
 
+// Enabled reports whether entries at the given level are written.
+// It can be used to skip building expensive fields.
+func (l *Logger) Enabled(lvl Level) bool {
+	return !l.isDiscard && lvl <= l.lvl
+}
+
 type writerFunc func([]byte) (int, error)
 
 func (fn writerFunc) Write(p []byte) (n int, err error) {
@@ -218,11 +266,11 @@ func (l *Logger) Writer(lvl Level) io.Writer {
 }
 
 func (l *Logger) write(msg string, lvl Level, ctx []Field) {
-	if l.isDiscard || lvl > l.lvl {
+	if !l.Enabled(lvl) {
 		return
 	}
 
-	e := newEvent(l.fmtr)
+	e := l.newEvent(l.fmtr)
 
 	var ts time.Time
 	if l.timeFn != nil {
@@ -231,12 +279,35 @@ func (l *Logger) write(msg string, lvl Level, ctx []Field) {
 
 	e.fmtr.AppendBeginMarker(e.buf)
 	e.fmtr.WriteMessage(e.buf, ts, lvl, msg)
-	e.buf.Write(l.ctx)
+	e.writeCtx(l.ctx, l.ctxKeys)
+
+	goCtx := l.hookContext()
+	if l.hook == nil || !l.hook.Enabled(goCtx, lvl) {
+		for _, field := range ctx {
+			field(e)
+		}
+		l.writeEvent(e)
+		return
+	}
 
+	// The fields are evaluated once to be written for the hook as well,
+	// recorded here as the caller and stack fields skip a fixed number
+	// of frames.
+	rec := newEvent(nil, nil, DuplicateKeepAll)
+	rec.record = true
 	for _, field := range ctx {
-		field(e)
+		field(rec)
 	}
 
+	e.replay(rec.recs)
+	l.writeEvent(e)
+
+	l.fire(goCtx, ts, msg, lvl, rec.recs)
+	putEvent(rec)
+}
+
+// writeEvent ends the entry in the event and writes it.
+func (l *Logger) writeEvent(e *Event) {
 	e.fmtr.AppendEndMarker(e.buf)
 	e.fmtr.AppendLineBreak(e.buf)
 
@@ -262,6 +262,21 @@ func TestLogger_Stack(t *testing.T) {
 	assert.Equal(t, want, buf.String())
 }
 
+func TestLogger_SpanContext(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+
+	span := &fakeSpan{ID: byte(2), Recording: true}
+	log.Info("some message", ctx.SpanID("sid", span), ctx.TraceFlags("flags", span))
+	log.Info("some message", ctx.SpanID("sid", &fakeSpan{}), ctx.TraceFlags("flags", &fakeSpan{}))
+
+	want := "lvl=info msg=\"some message\" sid=0200000000000000 flags=00\n" +
+		"lvl=info msg=\"some message\"\n"
+	assert.Equal(t, want, buf.String())
+}
+
 func TestLogger_Timestamp(t *testing.T) {
 	t.Parallel()
 
@@ -307,3 +322,14 @@ func (s *fakeSpan) SpanContext() trace.SpanContext {
 		Remote:  false,
 	})
 }
+
+func TestLogger_Enabled(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(&bytes.Buffer{}, logger.LogfmtFormat(), logger.Info)
+
+	assert.True(t, log.Enabled(logger.Error))
+	assert.True(t, log.Enabled(logger.Info))
+	assert.False(t, log.Enabled(logger.Debug))
+	assert.False(t, logger.New(io.Discard, logger.LogfmtFormat(), logger.Info).Enabled(logger.Error))
+}
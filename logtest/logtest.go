@@ -0,0 +1,363 @@
+// Package logtest implements a logger recording structured entries for tests.
+//
+//	log, rec := logtest.New(logger.Info)
+//	svc := NewService(log)
+//
+//	svc.Do()
+//
+//	rec.AssertLogged(t, logger.Info, "work done", logtest.F("items", 3))
+package logtest
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/internal/record"
+)
+
+// Field is a recorded context field.
+//
+// The value is one of string, bool, int64, uint64, float64, time.Time,
+// time.Duration, []any or nil. Interface values are recorded as their
+// formatted string.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// F returns a field, normalising the value to a recorded type, for use
+// with the filters and assertions.
+func F(key string, value any) Field {
+	return Field{Key: key, Value: normalise(value)}
+}
+
+// Entry is a recorded log entry.
+type Entry struct {
+	Time   time.Time
+	Level  logger.Level
+	Msg    string
+	Fields []Field
+}
+
+// Field returns the value of the last field with the given key.
+func (e Entry) Field(key string) (any, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// FieldMap returns the fields as a map, later fields overwriting earlier
+// fields with the same key.
+func (e Entry) FieldMap() map[string]any {
+	m := make(map[string]any, len(e.Fields))
+	for _, f := range e.Fields {
+		m[f.Key] = f.Value
+	}
+	return m
+}
+
+// String returns the entry in a logfmt like form.
+func (e Entry) String() string {
+	var sb strings.Builder
+	sb.WriteString("lvl=")
+	sb.WriteString(e.Level.String())
+	sb.WriteString(" msg=")
+	sb.WriteString(fmt.Sprintf("%q", e.Msg))
+	for _, f := range e.Fields {
+		sb.WriteString(" ")
+		sb.WriteString(f.Key)
+		sb.WriteString("=")
+		sb.WriteString(fmt.Sprintf("%v", f.Value))
+	}
+	return sb.String()
+}
+
+// Entries are recorded log entries.
+type Entries []Entry
+
+// FilterLevel returns the entries with the given level.
+func (e Entries) FilterLevel(lvl logger.Level) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return entry.Level == lvl
+	})
+}
+
+// FilterMessage returns the entries with the given message.
+func (e Entries) FilterMessage(msg string) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return entry.Msg == msg
+	})
+}
+
+// FilterMessageContains returns the entries with messages containing s.
+func (e Entries) FilterMessageContains(s string) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return strings.Contains(entry.Msg, s)
+	})
+}
+
+// FilterField returns the entries with the given field.
+func (e Entries) FilterField(field Field) Entries {
+	return e.Filter(func(entry Entry) bool {
+		return hasField(entry, field)
+	})
+}
+
+// FilterFieldKey returns the entries with a field with the given key.
+func (e Entries) FilterFieldKey(key string) Entries {
+	return e.Filter(func(entry Entry) bool {
+		_, ok := entry.Field(key)
+		return ok
+	})
+}
+
+// Filter returns the entries matching fn.
+func (e Entries) Filter(fn func(Entry) bool) Entries {
+	var filtered Entries
+	for _, entry := range e {
+		if fn(entry) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// Messages returns the messages of the entries.
+func (e Entries) Messages() []string {
+	msgs := make([]string, len(e))
+	for i, entry := range e {
+		msgs[i] = entry.Msg
+	}
+	return msgs
+}
+
+// Len returns the number of entries.
+func (e Entries) Len() int {
+	return len(e)
+}
+
+// Format returns the formatter to be used with the recorder.
+func Format() logger.Formatter {
+	return record.Format()
+}
+
+// Recorder records log entries written with the recorder formatter.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	entries Entries
+}
+
+// New returns a logger recording entries to the returned recorder.
+func New(lvl logger.Level) (*logger.Logger, *Recorder) {
+	rec := &Recorder{}
+	return logger.New(rec, Format(), lvl), rec
+}
+
+// Write records an entry.
+func (r *Recorder) Write(p []byte) (int, error) {
+	e, err := record.Decode(p)
+	if err != nil {
+		return 0, err
+	}
+
+	entry := Entry{
+		Time:  e.Time,
+		Level: e.Level,
+		Msg:   e.Msg,
+	}
+	if len(e.Fields) > 0 {
+		entry.Fields = make([]Field, len(e.Fields))
+		for i, f := range e.Fields {
+			entry.Fields[i] = Field{Key: f.Key, Value: f.Value}
+		}
+	}
+
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+
+	return len(p), nil
+}
+
+// Entries returns a copy of the recorded entries.
+func (r *Recorder) Entries() Entries {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.entries)
+}
+
+// Len returns the number of recorded entries.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.entries)
+}
+
+// Reset discards the recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// TestingT is the subset of testing.TB used by the assertions,
+// compatible with testify.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+type helper interface {
+	Helper()
+}
+
+// AssertLogged asserts that an entry with the level, message and fields
+// was recorded. The entry may have other fields.
+func (r *Recorder) AssertLogged(t TestingT, lvl logger.Level, msg string, fields ...Field) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+
+	entries := r.Entries()
+	for _, entry := range entries.FilterLevel(lvl).FilterMessage(msg) {
+		if hasFields(entry, fields) {
+			return true
+		}
+	}
+
+	want := Entry{Level: lvl, Msg: msg, Fields: fields}
+	t.Errorf("No entry matching:\n\t%s\nRecorded entries:%s", want, list(entries))
+	return false
+}
+
+// AssertNotLogged asserts that no entry with the level and message
+// was recorded.
+func (r *Recorder) AssertNotLogged(t TestingT, lvl logger.Level, msg string) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+
+	found := r.Entries().FilterLevel(lvl).FilterMessage(msg)
+	if len(found) == 0 {
+		return true
+	}
+
+	t.Errorf("Unexpected entries:%s", list(found))
+	return false
+}
+
+// AssertCount asserts the number of recorded entries.
+func (r *Recorder) AssertCount(t TestingT, n int) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+
+	entries := r.Entries()
+	if len(entries) == n {
+		return true
+	}
+
+	t.Errorf("Expected %d entries, got %d:%s", n, len(entries), list(entries))
+	return false
+}
+
+func list(entries Entries) string {
+	if len(entries) == 0 {
+		return " none"
+	}
+
+	var sb strings.Builder
+	for _, e := range entries {
+		sb.WriteString("\n\t")
+		sb.WriteString(e.String())
+	}
+	return sb.String()
+}
+
+func hasFields(entry Entry, fields []Field) bool {
+	for _, f := range fields {
+		if !hasField(entry, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasField(entry Entry, field Field) bool {
+	for _, f := range entry.Fields {
+		if f.Key == field.Key && equal(f.Value, field.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b any) bool {
+	// Recorded times have no monotonic clock or location.
+	if t, ok := a.(time.Time); ok {
+		u, ok := b.(time.Time)
+		return ok && t.Equal(u)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// normalise converts a value into the type it is recorded as.
+func normalise(v any) any {
+	switch v := v.(type) {
+	case nil, string, bool, int64, uint64, float64, time.Time, time.Duration:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case float32:
+		return float64(v)
+	case error:
+		return v.Error()
+	case []string:
+		return toAny(v)
+	case []int:
+		return toAny(v)
+	case []any:
+		return toAny(v)
+	case []byte:
+		// Bytes are recorded as an array of ints.
+		a := make([]any, len(v))
+		for i, b := range v {
+			a[i] = int64(b)
+		}
+		return a
+	default:
+		return fmt.Sprintf("%+v", v)
+	}
+}
+
+func toAny[T any](s []T) []any {
+	a := make([]any, len(s))
+	for i, v := range s {
+		a[i] = normalise(v)
+	}
+	return a
+}
@@ -0,0 +1,134 @@
+package logtest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/logtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	log, rec := logtest.New(logger.Info)
+	log = log.With(ctx.Str("svc", "api"))
+	c := logger.WithContext(context.Background(), log, ctx.Str("req_id", "abc"))
+
+	log.FromContext(c).Info("some message",
+		ctx.Str("str", "string"),
+		ctx.Strs("strs", []string{"a", "b"}),
+		ctx.Bool("bool", true),
+		ctx.Int("int", 1),
+		ctx.Uint("uint", 2),
+		ctx.Float64("float", 4.56),
+		ctx.Time("time", time.Unix(123, 0)),
+		ctx.Duration("dur", time.Second),
+		ctx.Error("error", errors.New("boom")),
+		ctx.Interface("obj", struct{ A int }{1}),
+		ctx.Interface("nil", nil),
+	)
+	log.Debug("filtered")
+
+	entries := rec.Entries()
+	require.Len(t, entries, 1)
+	want := logtest.Entry{
+		Level: logger.Info,
+		Msg:   "some message",
+		Fields: []logtest.Field{
+			{Key: "svc", Value: "api"},
+			{Key: "req_id", Value: "abc"},
+			{Key: "str", Value: "string"},
+			{Key: "strs", Value: []any{"a", "b"}},
+			{Key: "bool", Value: true},
+			{Key: "int", Value: int64(1)},
+			{Key: "uint", Value: uint64(2)},
+			{Key: "float", Value: 4.56},
+			{Key: "time", Value: time.Unix(123, 0)},
+			{Key: "dur", Value: time.Second},
+			{Key: "error", Value: "boom"},
+			{Key: "obj", Value: "{A:1}"},
+			{Key: "nil", Value: nil},
+		},
+	}
+	assert.Equal(t, want, entries[0])
+
+	v, ok := entries[0].Field("req_id")
+	assert.True(t, ok)
+	assert.Equal(t, "abc", v)
+	assert.Equal(t, "api", entries[0].FieldMap()["svc"])
+}
+
+func TestRecorder_WithTimestamp(t *testing.T) {
+	log, rec := logtest.New(logger.Info)
+	cancel := log.WithTimestamp()
+	t.Cleanup(cancel)
+
+	log.Info("some message")
+
+	entries := rec.Entries()
+	require.Len(t, entries, 1)
+	assert.False(t, entries[0].Time.IsZero())
+}
+
+func TestEntries_Filter(t *testing.T) {
+	log, rec := logtest.New(logger.Debug)
+
+	log.Info("started", ctx.Int("port", 80))
+	log.Debug("request", ctx.Str("path", "/a"), ctx.Int("status", 200))
+	log.Warn("request", ctx.Str("path", "/b"), ctx.Int("status", 404))
+	log.Error("request failed", ctx.Str("path", "/c"), ctx.Error("error", errors.New("boom")))
+
+	entries := rec.Entries()
+	assert.Equal(t, []string{"request", "request"}, entries.FilterMessage("request").Messages())
+	assert.Equal(t, []string{"request failed"}, entries.FilterLevel(logger.Error).Messages())
+	assert.Equal(t, 3, entries.FilterMessageContains("request").Len())
+	assert.Equal(t, []string{"request"}, entries.FilterField(logtest.F("status", 404)).FilterLevel(logger.Warn).Messages())
+	assert.Equal(t, []string{"request failed"}, entries.FilterField(logtest.F("error", errors.New("boom"))).Messages())
+	assert.Equal(t, 3, entries.FilterFieldKey("path").Len())
+	assert.Empty(t, entries.FilterField(logtest.F("status", "404")))
+
+	rec.Reset()
+	assert.Equal(t, 0, rec.Len())
+}
+
+func TestRecorder_Assertions(t *testing.T) {
+	log, rec := logtest.New(logger.Info)
+
+	log.Info("request", ctx.Str("path", "/a"), ctx.Int("status", 200), ctx.Strs("tags", []string{"x"}))
+
+	mockT := &mockT{}
+	assert.True(t, rec.AssertLogged(mockT, logger.Info, "request", logtest.F("status", 200), logtest.F("tags", []string{"x"})))
+	assert.True(t, rec.AssertNotLogged(mockT, logger.Error, "request"))
+	assert.True(t, rec.AssertCount(mockT, 1))
+	assert.Empty(t, mockT.errs)
+
+	assert.False(t, rec.AssertLogged(mockT, logger.Info, "request", logtest.F("status", 500)))
+	assert.False(t, rec.AssertNotLogged(mockT, logger.Info, "request"))
+	assert.False(t, rec.AssertCount(mockT, 2))
+	require.Len(t, mockT.errs, 3)
+	assert.Equal(t, "No entry matching:\n\tlvl=info msg=\"request\" status=500\n"+
+		"Recorded entries:\n\tlvl=info msg=\"request\" path=/a status=200 tags=[x]", mockT.errs[0])
+}
+
+func TestRecorder_AssertionsWithTestingT(t *testing.T) {
+	log, rec := logtest.New(logger.Info)
+	now := time.Now()
+
+	log.Info("some message", ctx.Time("at", now))
+
+	rec.AssertLogged(t, logger.Info, "some message", logtest.F("at", now))
+	rec.AssertCount(t, 1)
+}
+
+type mockT struct {
+	errs []string
+}
+
+func (m *mockT) Errorf(format string, args ...any) {
+	m.errs = append(m.errs, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,97 @@
+package logtest
+
+import (
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+)
+
+// Writer writes log entries to the output of a test, shown when the test
+// fails or is run in verbose mode.
+//
+// Like lines logged with t.Log from a helper, each entry is attributed to
+// the first caller outside of the logger packages. Entries written after
+// the test has completed are discarded, as logging after a test completes
+// panics.
+type Writer struct {
+	t testing.TB
+
+	mu   sync.Mutex
+	done bool
+}
+
+// NewWriter returns a writer writing to the output of the test.
+func NewWriter(t testing.TB) *Writer {
+	w := &Writer{t: t}
+	t.Cleanup(func() {
+		w.mu.Lock()
+		w.done = true
+		w.mu.Unlock()
+	})
+
+	return w
+}
+
+// Write writes an entry to the test output.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.done {
+		return len(p), nil
+	}
+
+	// The frames of the logger are not helpers, so t.Log would attribute
+	// each entry to the logger. The entry is written as t.Log writes it,
+	// attributed to the caller instead.
+	var sb strings.Builder
+	if file, line, ok := caller(); ok {
+		sb.WriteString(filepath.Base(file))
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(line))
+		sb.WriteString(": ")
+	}
+	sb.Write(p)
+	if len(p) == 0 || p[len(p)-1] != '\n' {
+		sb.WriteByte('\n')
+	}
+
+	_, _ = w.t.Output().Write([]byte(sb.String()))
+	return len(p), nil
+}
+
+// NewTestLogger returns a logger writing to the output of the test in
+// the console format without colors.
+func NewTestLogger(t testing.TB, lvl logger.Level, opts ...logger.FormatOption) *logger.Logger {
+	opts = append([]logger.FormatOption{logger.WithColor(false)}, opts...)
+	return logger.New(NewWriter(t), logger.ConsoleFormat(opts...), lvl)
+}
+
+// Packages whose frames are skipped when attributing entries.
+const (
+	loggerPkg  = "github.com/hamba/logger/v2."
+	logtestPkg = "github.com/hamba/logger/v2/logtest."
+)
+
+// caller returns the location of the first caller outside of the logger
+// packages.
+func caller() (string, int, bool) {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, loggerPkg) && !strings.HasPrefix(frame.Function, logtestPkg) {
+			return frame.File, frame.Line, frame.File != ""
+		}
+		if !more {
+			return "", 0, false
+		}
+	}
+}
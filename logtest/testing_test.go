@@ -0,0 +1,87 @@
+package logtest_test
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/logtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTestLogger(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	log := logtest.NewTestLogger(tb, logger.Info)
+
+	_, _, line, _ := runtime.Caller(0)
+	log.Info("some message", ctx.Str("key", "value"))
+	log.Debug("filtered")
+
+	want := "testing_test.go:" + strconv.Itoa(line+1) + ": INFO some message key=value\n"
+	assert.Equal(t, want, tb.out.String())
+}
+
+func TestNewTestLogger_WithFormatOptions(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	log := logtest.NewTestLogger(tb, logger.Info, logger.WithFloatPrecision(1))
+
+	log.Info("some message", ctx.Float64("key", 1.23))
+
+	assert.True(t, strings.HasSuffix(tb.out.String(), ": INFO some message key=1.2\n"))
+}
+
+func TestWriter_AttributesEntriesToCaller(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	log := logger.New(logtest.NewWriter(tb), logger.LogfmtFormat(), logger.Info).With(ctx.Str("svc", "api"))
+
+	_, _, line, _ := runtime.Caller(0)
+	log.Info("some message")
+	_, _ = log.Writer(logger.Info).Write([]byte("from writer"))
+
+	want := "testing_test.go:" + strconv.Itoa(line+1) + ": lvl=info msg=\"some message\" svc=api\n" +
+		"testing_test.go:" + strconv.Itoa(line+2) + ": lvl=info msg=\"from writer\" svc=api\n"
+	assert.Equal(t, want, tb.out.String())
+	assert.NotContains(t, tb.out.String(), "logger.go")
+}
+
+func TestWriter_DiscardsAfterTestCompletes(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	w := logtest.NewWriter(tb)
+
+	_, err := w.Write([]byte("before"))
+	assert.NoError(t, err)
+
+	tb.cleanup()
+
+	n, err := w.Write([]byte("after\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.True(t, strings.HasSuffix(tb.out.String(), ": before\n"))
+	assert.NotContains(t, tb.out.String(), "after")
+}
+
+type fakeTB struct {
+	testing.TB
+
+	out      bytes.Buffer
+	cleanups []func()
+}
+
+func (f *fakeTB) Output() io.Writer {
+	return &f.out
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeTB) cleanup() {
+	for _, fn := range f.cleanups {
+		fn()
+	}
+}
@@ -0,0 +1,344 @@
+package logger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hamba/logger/v2/internal/bytes"
+)
+
+// MessagePack type markers.
+const (
+	msgpackFixMap    = 0x80
+	msgpackFixArray  = 0x90
+	msgpackFixStr    = 0xa0
+	msgpackNil       = 0xc0
+	msgpackFalse     = 0xc2
+	msgpackTrue      = 0xc3
+	msgpackBin8      = 0xc4
+	msgpackBin16     = 0xc5
+	msgpackBin32     = 0xc6
+	msgpackExt8      = 0xc7
+	msgpackExt16     = 0xc8
+	msgpackExt32     = 0xc9
+	msgpackFloat32   = 0xca
+	msgpackFloat64   = 0xcb
+	msgpackUint8     = 0xcc
+	msgpackUint16    = 0xcd
+	msgpackUint32    = 0xce
+	msgpackUint64    = 0xcf
+	msgpackInt8      = 0xd0
+	msgpackInt16     = 0xd1
+	msgpackInt32     = 0xd2
+	msgpackInt64     = 0xd3
+	msgpackFixExt1   = 0xd4
+	msgpackFixExt4   = 0xd6
+	msgpackFixExt8   = 0xd7
+	msgpackFixExt16  = 0xd8
+	msgpackStr8      = 0xd9
+	msgpackStr16     = 0xda
+	msgpackStr32     = 0xdb
+	msgpackArray16   = 0xdc
+	msgpackArray32   = 0xdd
+	msgpackMap16     = 0xde
+	msgpackMap32     = 0xdf
+	msgpackNegFixInt = 0xe0
+
+	msgpackExtTime = 0xff // The timestamp extension type, -1.
+)
+
+// msgpackOpen is the count written in the header of maps and arrays
+// until they are closed. The position of each open header is marked in
+// the buffer, so the innermost one is found without a scan.
+const msgpackOpen = math.MaxUint32
+
+type msgpack struct{}
+
+// MsgPackFormat formats a log line as a MessagePack map.
+//
+// Values are written with their native MessagePack types: times with the
+// timestamp extension type, durations as integer nanoseconds, byte slices
+// as binary and arrays as MessagePack arrays. Entries are written without
+// line breaks, use a FrameWriter to split them into records.
+func MsgPackFormat() Formatter {
+	return &msgpack{}
+}
+
+func (m *msgpack) WriteMessage(buf *bytes.Buffer, ts time.Time, lvl Level, msg string) {
+	if !ts.IsZero() {
+		m.AppendKey(buf, TimestampKey)
+		m.AppendTime(buf, ts)
+	}
+	m.AppendKey(buf, LevelKey)
+	m.AppendString(buf, lvl.String())
+	m.AppendKey(buf, MessageKey)
+	m.AppendString(buf, msg)
+}
+
+// AppendBeginMarker appends an open map, counted when the entry ends.
+func (m *msgpack) AppendBeginMarker(buf *bytes.Buffer) {
+	openMsgpack(buf, msgpackMap32)
+}
+
+func (m *msgpack) AppendEndMarker(buf *bytes.Buffer) {
+	closeMsgpack(buf)
+}
+
+func (m *msgpack) AppendLineBreak(*bytes.Buffer) {}
+
+// AppendArrayStart appends an open array, counted when the array ends.
+func (m *msgpack) AppendArrayStart(buf *bytes.Buffer) {
+	openMsgpack(buf, msgpackArray32)
+}
+
+func (m *msgpack) AppendArraySep(*bytes.Buffer) {}
+
+func (m *msgpack) AppendArrayEnd(buf *bytes.Buffer) {
+	closeMsgpack(buf)
+}
+
+func (m *msgpack) AppendKey(buf *bytes.Buffer, key string) {
+	m.AppendString(buf, key)
+}
+
+func (m *msgpack) AppendString(buf *bytes.Buffer, s string) {
+	// Strings must be valid UTF-8.
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, string(utf8.RuneError))
+	}
+
+	n := len(s)
+	switch {
+	case n < 32:
+		buf.WriteByte(msgpackFixStr | byte(n))
+	case n <= math.MaxUint8:
+		appendMsgpackHead(buf, msgpackStr8, 1, uint64(n))
+	case n <= math.MaxUint16:
+		appendMsgpackHead(buf, msgpackStr16, 2, uint64(n))
+	default:
+		appendMsgpackHead(buf, msgpackStr32, 4, uint64(n))
+	}
+	buf.WriteString(s)
+}
+
+func (m *msgpack) AppendBytes(buf *bytes.Buffer, p []byte) {
+	n := len(p)
+	switch {
+	case n <= math.MaxUint8:
+		appendMsgpackHead(buf, msgpackBin8, 1, uint64(n))
+	case n <= math.MaxUint16:
+		appendMsgpackHead(buf, msgpackBin16, 2, uint64(n))
+	default:
+		appendMsgpackHead(buf, msgpackBin32, 4, uint64(n))
+	}
+	buf.Write(p)
+}
+
+func (m *msgpack) AppendBool(buf *bytes.Buffer, b bool) {
+	if b {
+		buf.WriteByte(msgpackTrue)
+		return
+	}
+	buf.WriteByte(msgpackFalse)
+}
+
+func (m *msgpack) AppendInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0:
+		m.AppendUint(buf, uint64(i))
+	case i >= -32:
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		appendMsgpackHead(buf, msgpackInt8, 1, uint64(i))
+	case i >= math.MinInt16:
+		appendMsgpackHead(buf, msgpackInt16, 2, uint64(i))
+	case i >= math.MinInt32:
+		appendMsgpackHead(buf, msgpackInt32, 4, uint64(i))
+	default:
+		appendMsgpackHead(buf, msgpackInt64, 8, uint64(i))
+	}
+}
+
+func (m *msgpack) AppendUint(buf *bytes.Buffer, i uint64) {
+	switch {
+	case i < 128:
+		buf.WriteByte(byte(i))
+	case i <= math.MaxUint8:
+		appendMsgpackHead(buf, msgpackUint8, 1, i)
+	case i <= math.MaxUint16:
+		appendMsgpackHead(buf, msgpackUint16, 2, i)
+	case i <= math.MaxUint32:
+		appendMsgpackHead(buf, msgpackUint32, 4, i)
+	default:
+		appendMsgpackHead(buf, msgpackUint64, 8, i)
+	}
+}
+
+func (m *msgpack) AppendFloat(buf *bytes.Buffer, f float64) {
+	// Floats are written as single precision when that is lossless.
+	if f32 := float32(f); float64(f32) == f || math.IsNaN(f) {
+		appendMsgpackHead(buf, msgpackFloat32, 4, uint64(math.Float32bits(f32)))
+		return
+	}
+	appendMsgpackHead(buf, msgpackFloat64, 8, math.Float64bits(f))
+}
+
+// AppendTime appends a time with the timestamp extension type, in the
+// smallest of its formats that holds the time.
+func (m *msgpack) AppendTime(buf *bytes.Buffer, t time.Time) {
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+	switch {
+	case nsec == 0 && sec >= 0 && sec <= math.MaxUint32:
+		buf.WriteByte(msgpackFixExt4)
+		appendMsgpackHead(buf, msgpackExtTime, 4, uint64(sec))
+	case sec >= 0 && sec>>34 == 0:
+		buf.WriteByte(msgpackFixExt8)
+		appendMsgpackHead(buf, msgpackExtTime, 8, nsec<<34|uint64(sec))
+	default:
+		buf.WriteByte(msgpackExt8)
+		buf.WriteByte(12)
+		appendMsgpackHead(buf, msgpackExtTime, 4, nsec)
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(sec))
+		buf.Write(b[:])
+	}
+}
+
+func (m *msgpack) AppendDuration(buf *bytes.Buffer, d time.Duration) {
+	m.AppendInt(buf, int64(d))
+}
+
+func (m *msgpack) AppendInterface(buf *bytes.Buffer, v any) {
+	if v == nil {
+		buf.WriteByte(msgpackNil)
+		return
+	}
+
+	m.AppendString(buf, fmt.Sprintf("%+v", v))
+}
+
+// appendMsgpackHead appends a type marker followed by n in size bytes.
+func appendMsgpackHead(buf *bytes.Buffer, marker byte, size int, n uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	buf.WriteByte(marker)
+	buf.Write(b[8-size:])
+}
+
+// openMsgpack appends an open map or array header, marking its position.
+func openMsgpack(buf *bytes.Buffer, marker byte) {
+	buf.PushMark(buf.Len())
+	appendMsgpackHead(buf, marker, 4, msgpackOpen)
+}
+
+// closeMsgpack closes the innermost open map or array in buf, writing
+// its count in the smallest header that holds it.
+func closeMsgpack(buf *bytes.Buffer) {
+	b := buf.Bytes()
+	start := buf.PopMark()
+	if start < 0 || !isOpenMsgpack(b, start) {
+		return
+	}
+
+	isMap := b[start] == msgpackMap32
+	n := 0
+	for i := start + 5; i < len(b); n++ {
+		i = skipMsgpack(b, i)
+	}
+	if isMap {
+		n /= 2
+	}
+
+	var head [5]byte
+	size := 5
+	switch {
+	case n < 16 && isMap:
+		head[0], size = msgpackFixMap|byte(n), 1
+	case n < 16:
+		head[0], size = msgpackFixArray|byte(n), 1
+	case n <= math.MaxUint16:
+		head[0], size = msgpackArray16, 3
+		if isMap {
+			head[0] = msgpackMap16
+		}
+		binary.BigEndian.PutUint16(head[1:], uint16(n))
+	default:
+		head[0] = b[start]
+		binary.BigEndian.PutUint32(head[1:], uint32(n))
+	}
+
+	copy(b[start:], head[:size])
+	if size < 5 {
+		copy(b[start+size:], b[start+5:])
+		buf.Truncate(len(b) - 5 + size)
+	}
+}
+
+// isOpenMsgpack reports whether an open map or array header is at i.
+func isOpenMsgpack(b []byte, i int) bool {
+	return i+5 <= len(b) && (b[i] == msgpackMap32 || b[i] == msgpackArray32) &&
+		binary.BigEndian.Uint32(b[i+1:]) == msgpackOpen
+}
+
+// skipMsgpack returns the position after the item at i, including the
+// items of maps and arrays.
+func skipMsgpack(b []byte, i int) int {
+	c := b[i]
+	switch {
+	case c < msgpackFixMap || c >= msgpackNegFixInt:
+		return i + 1
+	case c < msgpackFixArray:
+		return skipMsgpackItems(b, i+1, 2*int(c&0x0f))
+	case c < msgpackFixStr:
+		return skipMsgpackItems(b, i+1, int(c&0x0f))
+	case c <= msgpackFixStr|0x1f:
+		return i + 1 + int(c&0x1f)
+	}
+
+	switch c {
+	case msgpackBin8, msgpackStr8:
+		return i + 2 + int(b[i+1])
+	case msgpackBin16, msgpackStr16:
+		return i + 3 + int(binary.BigEndian.Uint16(b[i+1:]))
+	case msgpackBin32, msgpackStr32:
+		return i + 5 + int(binary.BigEndian.Uint32(b[i+1:]))
+	case msgpackExt8:
+		return i + 3 + int(b[i+1])
+	case msgpackExt16:
+		return i + 4 + int(binary.BigEndian.Uint16(b[i+1:]))
+	case msgpackExt32:
+		return i + 6 + int(binary.BigEndian.Uint32(b[i+1:]))
+	case msgpackUint8, msgpackInt8:
+		return i + 2
+	case msgpackUint16, msgpackInt16:
+		return i + 3
+	case msgpackFloat32, msgpackUint32, msgpackInt32:
+		return i + 5
+	case msgpackFloat64, msgpackUint64, msgpackInt64:
+		return i + 9
+	case msgpackArray16:
+		return skipMsgpackItems(b, i+3, int(binary.BigEndian.Uint16(b[i+1:])))
+	case msgpackMap16:
+		return skipMsgpackItems(b, i+3, 2*int(binary.BigEndian.Uint16(b[i+1:])))
+	case msgpackArray32:
+		return skipMsgpackItems(b, i+5, int(binary.BigEndian.Uint32(b[i+1:])))
+	case msgpackMap32:
+		return skipMsgpackItems(b, i+5, 2*int(binary.BigEndian.Uint32(b[i+1:])))
+	}
+
+	if c >= msgpackFixExt1 && c <= msgpackFixExt16 {
+		return i + 2 + 1<<(c-msgpackFixExt1)
+	}
+	return i + 1
+}
+
+func skipMsgpackItems(b []byte, i, n int) int {
+	for ; n > 0 && i < len(b); n-- {
+		i = skipMsgpack(b, i)
+	}
+	return i
+}
@@ -0,0 +1,246 @@
+package logger_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/internal/bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestMsgPackFormat(t *testing.T) {
+	fmtr := logger.MsgPackFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendBeginMarker(buf)
+	fmtr.WriteMessage(buf, time.Unix(123, 0).UTC(), logger.Error, "msg")
+	fmtr.AppendKey(buf, "error")
+	fmtr.AppendString(buf, "err")
+	fmtr.AppendEndMarker(buf)
+	fmtr.AppendLineBreak(buf)
+
+	want := []byte{
+		0x84,
+		0xa2, 't', 's', 0xd6, 0xff, 0x00, 0x00, 0x00, 0x7b,
+		0xa3, 'l', 'v', 'l', 0xa4, 'e', 'r', 'o', 'r',
+		0xa3, 'm', 's', 'g', 0xa3, 'm', 's', 'g',
+		0xa5, 'e', 'r', 'r', 'o', 'r', 0xa3, 'e', 'r', 'r',
+	}
+	assert.Equal(t, want, buf.Bytes())
+}
+
+func TestMsgPackFormat_Array(t *testing.T) {
+	fmtr := logger.MsgPackFormat()
+
+	buf := bytes.NewBuffer(512)
+	fmtr.AppendArrayStart(buf)
+	fmtr.AppendInt(buf, 1)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendArrayStart(buf)
+	fmtr.AppendArrayEnd(buf)
+	fmtr.AppendArraySep(buf)
+	fmtr.AppendInt(buf, 2)
+	fmtr.AppendArrayEnd(buf)
+
+	assert.Equal(t, []byte{0x93, 0x01, 0x90, 0x02}, buf.Bytes())
+}
+
+func TestMsgPackFormat_Values(t *testing.T) {
+	tests := []struct {
+		name   string
+		append func(logger.Formatter, *bytes.Buffer)
+		want   []byte
+	}{
+		{
+			name:   "fixint",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInt(buf, 127) },
+			want:   []byte{0x7f},
+		},
+		{
+			name:   "uint8",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInt(buf, 200) },
+			want:   []byte{0xcc, 0xc8},
+		},
+		{
+			name:   "uint64",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendUint(buf, math.MaxUint64) },
+			want:   []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		},
+		{
+			name:   "negative fixint",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInt(buf, -32) },
+			want:   []byte{0xe0},
+		},
+		{
+			name:   "int16",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInt(buf, -1000) },
+			want:   []byte{0xd1, 0xfc, 0x18},
+		},
+		{
+			name:   "int64",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInt(buf, math.MinInt64) },
+			want:   []byte{0xd3, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+		{
+			name:   "float32",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendFloat(buf, 1.5) },
+			want:   []byte{0xca, 0x3f, 0xc0, 0x00, 0x00},
+		},
+		{
+			name:   "float64",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendFloat(buf, 1.1) },
+			want:   []byte{0xcb, 0x3f, 0xf1, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9a},
+		},
+		{
+			name:   "bool",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendBool(buf, true); f.AppendBool(buf, false) },
+			want:   []byte{0xc3, 0xc2},
+		},
+		{
+			name:   "str8",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendString(buf, strings.Repeat("a", 32)) },
+			want:   append([]byte{0xd9, 0x20}, strings.Repeat("a", 32)...),
+		},
+		{
+			name:   "invalid utf8 string",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendString(buf, "a\xff") },
+			want:   []byte{0xa4, 'a', 0xef, 0xbf, 0xbd},
+		},
+		{
+			name:   "bin8",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.(logger.BytesAppender).AppendBytes(buf, []byte{1, 2}) },
+			want:   []byte{0xc4, 0x02, 0x01, 0x02},
+		},
+		{
+			name:   "timestamp64",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendTime(buf, time.Unix(1, 1)) },
+			want:   []byte{0xd7, 0xff, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01},
+		},
+		{
+			name:   "timestamp96",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendTime(buf, time.Unix(-1, 0)) },
+			want: []byte{
+				0xc7, 0x0c, 0xff, 0x00, 0x00, 0x00, 0x00,
+				0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			},
+		},
+		{
+			name:   "duration",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendDuration(buf, time.Microsecond) },
+			want:   []byte{0xcd, 0x03, 0xe8},
+		},
+		{
+			name:   "interface",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInterface(buf, struct{ A int }{A: 1}) },
+			want:   []byte{0xa5, '{', 'A', ':', '1', '}'},
+		},
+		{
+			name:   "nil interface",
+			append: func(f logger.Formatter, buf *bytes.Buffer) { f.AppendInterface(buf, nil) },
+			want:   []byte{0xc0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(512)
+			test.append(logger.MsgPackFormat(), buf)
+
+			assert.Equal(t, test.want, buf.Bytes())
+		})
+	}
+}
+
+type msgpackEntry struct {
+	Lvl   string        `msgpack:"lvl"`
+	Msg   string        `msgpack:"msg"`
+	App   string        `msgpack:"app"`
+	Ctx   []int         `msgpack:"ctx"`
+	Int   int64         `msgpack:"int"`
+	Uint  uint64        `msgpack:"uint"`
+	Float float64       `msgpack:"float"`
+	Bool  bool          `msgpack:"bool"`
+	Dur   time.Duration `msgpack:"dur"`
+	Time  time.Time     `msgpack:"time"`
+	Past  time.Time     `msgpack:"past"`
+	Bytes []byte        `msgpack:"bytes"`
+	Strs  []string      `msgpack:"strs"`
+	Ints  []int         `msgpack:"ints"`
+	Long  string        `msgpack:"long"`
+	Nil   *string       `msgpack:"nil"`
+}
+
+func TestMsgPackFormat_RoundTrip(t *testing.T) {
+	var sb strings.Builder
+	log := logger.New(&sb, logger.MsgPackFormat(), logger.Info).
+		With(ctx.Str("app", "test"), ctx.Ints("ctx", []int{1, 2}))
+
+	past := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+	log.Info("some message",
+		ctx.Int("int", -5000),
+		ctx.Uint64("uint", math.MaxUint64),
+		ctx.Float64("float", 1.1),
+		ctx.Bool("bool", true),
+		ctx.Duration("dur", time.Second),
+		ctx.Time("time", time.Unix(1541573670, 5e8)),
+		ctx.Time("past", past),
+		ctx.Bytes("bytes", []byte{0, 1, 2}),
+		ctx.Strs("strs", []string{"a", "b"}),
+		ctx.Ints("ints", make([]int, 20)),
+		ctx.Str("long", strings.Repeat("x", 70000)),
+		ctx.Interface("nil", nil),
+	)
+
+	// The entry has more than 15 fields, so is written as a map 16.
+	require.Equal(t, byte(0xde), sb.String()[0])
+
+	r := strings.NewReader(sb.String())
+	dec := msgpack.NewDecoder(r)
+	dec.DisallowUnknownFields(true)
+	var got msgpackEntry
+	err := dec.Decode(&got)
+
+	require.NoError(t, err)
+	want := msgpackEntry{
+		Lvl:   "info",
+		Msg:   "some message",
+		App:   "test",
+		Ctx:   []int{1, 2},
+		Int:   -5000,
+		Uint:  math.MaxUint64,
+		Float: 1.1,
+		Bool:  true,
+		Dur:   time.Second,
+		Time:  time.Unix(1541573670, 5e8),
+		Past:  past.Local(),
+		Bytes: []byte{0, 1, 2},
+		Strs:  []string{"a", "b"},
+		Ints:  make([]int, 20),
+		Long:  strings.Repeat("x", 70000),
+	}
+	assert.Equal(t, want, got)
+	assert.Zero(t, r.Len())
+}
+
+func TestMsgPackFormat_RoundTripDuplicates(t *testing.T) {
+	var sb strings.Builder
+	log := logger.New(&sb, logger.MsgPackFormat(), logger.Info).
+		WithDuplicatePolicy(logger.DuplicateLastWins).
+		With(ctx.Strs("a", []string{"x"}), ctx.Int("b", 1))
+
+	log.Info("msg", ctx.Strs("a", []string{"y", "z"}))
+
+	dec := msgpack.NewDecoder(strings.NewReader(sb.String()))
+	dec.UseLooseInterfaceDecoding(true)
+	got, err := dec.DecodeMap()
+	require.NoError(t, err)
+
+	want := map[string]any{"lvl": "info", "msg": "msg", "a": []any{"y", "z"}, "b": int64(1)}
+	assert.Equal(t, want, got)
+}
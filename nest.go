@@ -0,0 +1,262 @@
+package logger
+
+import (
+	stdbytes "bytes"
+	"strconv"
+	"sync"
+
+	"github.com/hamba/logger/v2/internal/bytes"
+)
+
+var nestPool = &sync.Pool{
+	New: func() any {
+		return &nester{
+			buf: bytes.NewBuffer(512),
+		}
+	},
+}
+
+type nestNode struct {
+	name  []byte
+	val   []byte
+	first int
+	last  int
+	next  int
+
+	// key is the full key of a value, and off the offset of the name in
+	// the full keys of the node.
+	key []byte
+	off int
+	// flat is set on objects that conflict with a value, which are
+	// written as flat keys.
+	flat bool
+}
+
+// nester rewrites a flat JSON object, nesting dotted keys into objects.
+type nester struct {
+	buf   *bytes.Buffer
+	nodes []nestNode
+}
+
+// splitFunc splits a key into the name of the object it belongs to and
+// the remainder of the key. If the key should not be nested, ok is false.
+type splitFunc func(key []byte) (name, rest []byte, ok bool)
+
+// splitDots splits keys on every dot.
+func splitDots(key []byte) (name, rest []byte, ok bool) {
+	return stdbytes.Cut(key, []byte{'.'})
+}
+
+// nestJSON rewrites the flat JSON object in buf, nesting keys into objects
+// as determined by split. Keys sharing a prefix are grouped into the same
+// object in order of first appearance.
+//
+// If a nested key conflicts with an existing value, it is kept as is. If
+// a value conflicts with existing nested keys, they are kept as is.
+func nestJSON(buf *bytes.Buffer, split splitFunc) {
+	n := nestPool.Get().(*nester)
+	defer nestPool.Put(n)
+
+	n.buf.Reset()
+	n.nodes = append(n.nodes[:0], nestNode{first: -1, last: -1, next: -1})
+
+	b := buf.Bytes()
+	for i := 1; i < len(b); {
+		if b[i] != '"' {
+			i++
+			continue
+		}
+
+		end := skipJSONString(b, i)
+		key := unquoteJSONKey(b[i : end+1])
+		i = end + 2 // Skip the closing quote and colon.
+		end = skipJSONValue(b, i)
+		val := b[i:end]
+		i = end
+
+		n.insert(key, val, split)
+	}
+
+	n.write(0)
+
+	buf.Reset()
+	buf.Write(n.buf.Bytes())
+}
+
+func (n *nester) insert(key, val []byte, split splitFunc) {
+	full, parent, off := key, 0, 0
+	for {
+		name, rest, ok := split(key)
+		if !ok {
+			if child := n.find(parent, key); child != -1 && n.nodes[child].val == nil {
+				// The key already holds nested keys, keep them flat.
+				n.nodes[child].flat = true
+			}
+			n.add(parent, key, val, full, off)
+			return
+		}
+
+		child := n.find(parent, name)
+		switch {
+		case child == -1:
+			child = n.add(parent, name, nil, nil, off)
+		case n.nodes[child].val != nil && !n.expand(child):
+			// The prefix already holds a value, keep the key flat.
+			n.add(parent, key, val, full, off)
+			return
+		}
+		parent, key, off = child, rest, off+len(name)+1
+	}
+}
+
+// expand replaces the object value of the node with its members, so keys
+// can be nested into it. It reports whether the value is an object.
+func (n *nester) expand(idx int) bool {
+	node := n.nodes[idx]
+	if len(node.val) < 2 || node.val[0] != '{' {
+		return false
+	}
+	n.nodes[idx].val = nil
+
+	val, off := node.val, node.off+len(node.name)+1
+	for i := 1; i < len(val)-1; {
+		if val[i] != '"' {
+			i++
+			continue
+		}
+
+		end := skipJSONString(val, i)
+		name := unquoteJSONKey(val[i : end+1])
+		i = end + 2 // Skip the closing quote and colon.
+		end = skipJSONValue(val, i)
+
+		key := append(append(append([]byte{}, node.key...), '.'), name...)
+		n.add(idx, name, val[i:end], key, off)
+		i = end
+	}
+	return true
+}
+
+// find returns the node with the name that keys can be nested into.
+func (n *nester) find(parent int, name []byte) int {
+	for i := n.nodes[parent].first; i != -1; i = n.nodes[i].next {
+		if !n.nodes[i].flat && stdbytes.Equal(n.nodes[i].name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (n *nester) add(parent int, name, val, key []byte, off int) int {
+	idx := len(n.nodes)
+	n.nodes = append(n.nodes, nestNode{name: name, val: val, first: -1, last: -1, next: -1, key: key, off: off})
+
+	p := &n.nodes[parent]
+	if p.last == -1 {
+		p.first = idx
+	} else {
+		n.nodes[p.last].next = idx
+	}
+	p.last = idx
+	return idx
+}
+
+func (n *nester) write(idx int) {
+	n.buf.WriteByte('{')
+	for i := n.nodes[idx].first; i != -1; i = n.nodes[i].next {
+		if i != n.nodes[idx].first {
+			n.buf.WriteByte(',')
+		}
+		if n.nodes[i].flat {
+			n.writeFlat(i, n.nodes[i].off)
+			continue
+		}
+		appendString(n.buf, string(n.nodes[i].name), true)
+		n.buf.WriteByte(':')
+		if n.nodes[i].val != nil {
+			n.buf.Write(n.nodes[i].val)
+			continue
+		}
+		n.write(i)
+	}
+	n.buf.WriteByte('}')
+}
+
+// writeFlat writes the values of the object with their keys from off.
+func (n *nester) writeFlat(idx, off int) {
+	for i := n.nodes[idx].first; i != -1; i = n.nodes[i].next {
+		if i != n.nodes[idx].first {
+			n.buf.WriteByte(',')
+		}
+		if n.nodes[i].val == nil {
+			n.writeFlat(i, off)
+			continue
+		}
+		appendString(n.buf, string(n.nodes[i].key[off:]), true)
+		n.buf.WriteByte(':')
+		n.buf.Write(n.nodes[i].val)
+	}
+}
+
+// hasDottedKey reports whether a key of the JSON object members in b,
+// each starting with a comma, holds a dot.
+func hasDottedKey(b []byte) bool {
+	for i := 0; i < len(b) && b[i] == ','; {
+		end := skipJSONString(b, i+1)
+		if stdbytes.IndexByte(b[i+1:end], '.') >= 0 {
+			return true
+		}
+		i = skipJSONValue(b, end+2)
+	}
+	return false
+}
+
+// skipJSONString returns the index of the closing quote of the
+// string starting at i.
+func skipJSONString(b []byte, i int) int {
+	for i++; i < len(b); i++ {
+		switch b[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(b)
+}
+
+// skipJSONValue returns the index after the value starting at i.
+func skipJSONValue(b []byte, i int) int {
+	depth := 0
+	for ; i < len(b); i++ {
+		switch b[i] {
+		case '"':
+			i = skipJSONString(b, i)
+		case '[', '{':
+			depth++
+		case ']', '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(b)
+}
+
+// unquoteJSONKey returns the key from its quoted form.
+func unquoteJSONKey(b []byte) []byte {
+	if stdbytes.IndexByte(b, '\\') == -1 {
+		return b[1 : len(b)-1]
+	}
+
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return b[1 : len(b)-1]
+	}
+	return []byte(s)
+}
@@ -0,0 +1,159 @@
+// Package otellog implements a bridge from logger to the OpenTelemetry logs API.
+//
+// Entries are converted into OpenTelemetry log records and emitted to a
+// LoggerProvider. The writer must be used with the bridge formatter:
+//
+//	w := otellog.NewWriter(provider, "my-service")
+//	log := logger.New(w, otellog.Format(), logger.Info)
+package otellog
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/internal/otelconv"
+	"github.com/hamba/logger/v2/internal/record"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Default keys of the fields holding the trace context.
+const (
+	DefaultTraceIDKey    = "trace_id"
+	DefaultSpanIDKey     = "span_id"
+	DefaultTraceFlagsKey = "trace_flags"
+)
+
+// Format returns the formatter to be used with the bridge writer.
+func Format() logger.Formatter {
+	return record.Format()
+}
+
+// Option configures the bridge writer.
+type Option func(*Writer)
+
+// WithTraceKeys sets the keys of the fields holding the trace ID, span ID
+// and trace flags. Fields with these keys are used as the trace context of
+// the emitted record rather than as attributes.
+func WithTraceKeys(traceID, spanID, traceFlags string) Option {
+	return func(w *Writer) {
+		w.traceIDKey = traceID
+		w.spanIDKey = spanID
+		w.traceFlagsKey = traceFlags
+	}
+}
+
+// Writer emits log entries as OpenTelemetry log records.
+type Writer struct {
+	log log.Logger
+
+	traceIDKey    string
+	spanIDKey     string
+	traceFlagsKey string
+}
+
+// NewWriter returns a writer emitting records to a logger with the given
+// name from the provider.
+func NewWriter(provider log.LoggerProvider, name string, opts ...Option) *Writer {
+	w := &Writer{
+		log:           provider.Logger(name),
+		traceIDKey:    DefaultTraceIDKey,
+		spanIDKey:     DefaultSpanIDKey,
+		traceFlagsKey: DefaultTraceFlagsKey,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Write decodes an entry and emits it as a log record.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	e, err := record.Decode(p)
+	if err != nil {
+		return 0, err
+	}
+
+	var r log.Record
+	if !e.Time.IsZero() {
+		r.SetTimestamp(e.Time)
+	}
+	r.SetObservedTimestamp(time.Now())
+	r.SetSeverity(severity(e.Level))
+	r.SetSeverityText(e.Level.String())
+	r.SetBody(attribute.StringValue(e.Msg))
+
+	var sc trace.SpanContextConfig
+	for _, f := range e.Fields {
+		switch f.Key {
+		case w.traceIDKey:
+			if s, ok := f.Value.(string); ok {
+				if id, err := trace.TraceIDFromHex(s); err == nil {
+					sc.TraceID = id
+					continue
+				}
+			}
+		case w.spanIDKey:
+			if s, ok := f.Value.(string); ok {
+				if id, err := trace.SpanIDFromHex(s); err == nil {
+					sc.SpanID = id
+					continue
+				}
+			}
+		case w.traceFlagsKey:
+			if flags, ok := traceFlags(f.Value); ok {
+				sc.TraceFlags = flags
+				continue
+			}
+		}
+
+		r.AddAttributes(otelconv.Attribute(f))
+	}
+
+	ctx := context.Background()
+	if spanCtx := trace.NewSpanContext(sc); spanCtx.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, spanCtx)
+	}
+
+	w.log.Emit(ctx, r)
+
+	return len(p), nil
+}
+
+func severity(lvl logger.Level) log.Severity {
+	switch lvl {
+	case logger.Trace:
+		return log.SeverityTrace
+	case logger.Debug:
+		return log.SeverityDebug
+	case logger.Info:
+		return log.SeverityInfo
+	case logger.Warn:
+		return log.SeverityWarn
+	case logger.Error:
+		return log.SeverityError
+	case logger.Crit:
+		return log.SeverityFatal
+	default:
+		return log.SeverityUndefined
+	}
+}
+
+func traceFlags(v any) (trace.TraceFlags, bool) {
+	switch v := v.(type) {
+	case string:
+		b, err := strconv.ParseUint(v, 16, 8)
+		return trace.TraceFlags(b), err == nil
+	case int64:
+		return trace.TraceFlags(v), v >= 0 && v <= math.MaxUint8
+	case uint64:
+		return trace.TraceFlags(v), v <= math.MaxUint8
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,145 @@
+package otellog_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/otellog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otlog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/logtest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWriter(t *testing.T) {
+	rec := logtest.NewRecorder()
+	w := otellog.NewWriter(rec, "test")
+
+	log := logger.New(w, otellog.Format(), logger.Info).With(ctx.Str("svc", "api"))
+
+	log.Error("some message",
+		ctx.Str("str", "string"),
+		ctx.Strs("strs", []string{"a", "b"}),
+		ctx.Bool("bool", true),
+		ctx.Int("int", 1),
+		ctx.Uint64("uint", 2),
+		ctx.Float64("float", 4.56),
+		ctx.Time("time", time.Unix(123, 0)),
+		ctx.Duration("dur", time.Second),
+		ctx.Interface("nil", nil),
+	)
+
+	recs := rec.Result()[logtest.Scope{Name: "test"}]
+	require.Len(t, recs, 1)
+	got := recs[0]
+	assert.Equal(t, otlog.SeverityError, got.Severity)
+	assert.Equal(t, "eror", got.SeverityText)
+	assert.Equal(t, attribute.StringValue("some message"), got.Body)
+	assert.True(t, got.Timestamp.IsZero())
+	assert.False(t, got.ObservedTimestamp.IsZero())
+	want := []attribute.KeyValue{
+		attribute.String("svc", "api"),
+		attribute.String("str", "string"),
+		attribute.Slice("strs", attribute.StringValue("a"), attribute.StringValue("b")),
+		attribute.Bool("bool", true),
+		attribute.Int64("int", 1),
+		attribute.Int64("uint", 2),
+		attribute.Float64("float", 4.56),
+		attribute.Int64("time", 123e9),
+		attribute.Int64("dur", int64(time.Second)),
+		{Key: "nil"},
+	}
+	assert.Equal(t, want, got.Attributes)
+	assert.False(t, trace.SpanContextFromContext(got.Context).IsValid())
+}
+
+func TestWriter_Severities(t *testing.T) {
+	tests := []struct {
+		lvl  logger.Level
+		want otlog.Severity
+	}{
+		{lvl: logger.Trace, want: otlog.SeverityTrace},
+		{lvl: logger.Debug, want: otlog.SeverityDebug},
+		{lvl: logger.Info, want: otlog.SeverityInfo},
+		{lvl: logger.Warn, want: otlog.SeverityWarn},
+		{lvl: logger.Error, want: otlog.SeverityError},
+		{lvl: logger.Crit, want: otlog.SeverityFatal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.lvl.String(), func(t *testing.T) {
+			rec := logtest.NewRecorder()
+			log := logger.New(otellog.NewWriter(rec, "test"), otellog.Format(), logger.Trace)
+
+			_, _ = log.Writer(test.lvl).Write([]byte("some message"))
+
+			recs := rec.Result()[logtest.Scope{Name: "test"}]
+			require.Len(t, recs, 1)
+			assert.Equal(t, test.want, recs[0].Severity)
+		})
+	}
+}
+
+func TestWriter_Timestamp(t *testing.T) {
+	rec := logtest.NewRecorder()
+	log := logger.New(otellog.NewWriter(rec, "test"), otellog.Format(), logger.Info)
+	cancel := log.WithTimestamp()
+	t.Cleanup(cancel)
+
+	log.Info("some message")
+
+	recs := rec.Result()[logtest.Scope{Name: "test"}]
+	require.Len(t, recs, 1)
+	assert.WithinDuration(t, time.Now(), recs[0].Timestamp, time.Second)
+}
+
+func TestWriter_TraceContext(t *testing.T) {
+	rec := logtest.NewRecorder()
+	log := logger.New(otellog.NewWriter(rec, "test"), otellog.Format(), logger.Info)
+
+	log.Info("some message",
+		ctx.Str("trace_id", "0102030405060708090a0b0c0d0e0f10"),
+		ctx.Str("span_id", "0102030405060708"),
+		ctx.Str("trace_flags", "01"),
+		ctx.Str("user", "bob"),
+	)
+
+	recs := rec.Result()[logtest.Scope{Name: "test"}]
+	require.Len(t, recs, 1)
+	sc := trace.SpanContextFromContext(recs[0].Context)
+	assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", sc.TraceID().String())
+	assert.Equal(t, "0102030405060708", sc.SpanID().String())
+	assert.True(t, sc.IsSampled())
+	assert.Equal(t, []attribute.KeyValue{attribute.String("user", "bob")}, recs[0].Attributes)
+}
+
+func TestWriter_TraceKeys(t *testing.T) {
+	rec := logtest.NewRecorder()
+	w := otellog.NewWriter(rec, "test", otellog.WithTraceKeys("tid", "sid", "flags"))
+	log := logger.New(w, otellog.Format(), logger.Info)
+
+	log.Info("some message",
+		ctx.Str("tid", "0102030405060708090a0b0c0d0e0f10"),
+		ctx.Str("sid", "0102030405060708"),
+		ctx.Str("trace_id", "invalid"),
+	)
+
+	recs := rec.Result()[logtest.Scope{Name: "test"}]
+	require.Len(t, recs, 1)
+	sc := trace.SpanContextFromContext(recs[0].Context)
+	assert.True(t, sc.IsValid())
+	assert.False(t, sc.IsSampled())
+	assert.Equal(t, []attribute.KeyValue{attribute.String("trace_id", "invalid")}, recs[0].Attributes)
+}
+
+func TestWriter_InvalidEntry(t *testing.T) {
+	w := otellog.NewWriter(logtest.NewRecorder(), "test")
+
+	_, err := w.Write([]byte("lvl=info msg=test\n"))
+
+	assert.Error(t, err)
+}
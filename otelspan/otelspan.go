@@ -0,0 +1,132 @@
+// Package otelspan records log entries on OpenTelemetry spans.
+//
+// Entries logged through a Logger derived with Ctx are added as events
+// to the recording span in the context:
+//
+//	log = log.WithHook(otelspan.NewHook(otelspan.WithErrorStatus()))
+//	log.Ctx(ctx).Info("processing item", ctx.Int("id", id))
+//
+// The span is taken from the context with trace.SpanFromContext rather than
+// given as a ctx.Span, as adding events and setting the status needs the
+// full trace.Span. Entries are only rendered for the hook when the span
+// is recording.
+package otelspan
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/internal/otelconv"
+	"github.com/hamba/logger/v2/internal/record"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Default keys used by the hook.
+const (
+	DefaultSeverityKey = "log.severity"
+	DefaultErrorKey    = "error"
+)
+
+// Option configures the span hook.
+type Option func(*Hook)
+
+// WithErrorStatus sets the span status to error on Error and Crit entries,
+// recording the entry as an exception on the span.
+func WithErrorStatus() Option {
+	return func(h *Hook) {
+		h.errStatus = true
+	}
+}
+
+// WithSeverityKey sets the key of the event attribute holding the level.
+// An empty key omits the attribute.
+func WithSeverityKey(key string) Option {
+	return func(h *Hook) {
+		h.severityKey = key
+	}
+}
+
+// WithErrorKey sets the key of the field holding the error message of
+// an entry. The message is used as the recorded exception, falling back
+// to the entry message.
+func WithErrorKey(key string) Option {
+	return func(h *Hook) {
+		h.errKey = key
+	}
+}
+
+// Hook adds log entries as events on the span in the context
+// given to the logger.
+type Hook struct {
+	fmtr logger.Formatter
+
+	errStatus   bool
+	severityKey string
+	errKey      string
+}
+
+// NewHook returns a span hook.
+func NewHook(opts ...Option) *Hook {
+	h := &Hook{
+		fmtr:        record.Format(),
+		severityKey: DefaultSeverityKey,
+		errKey:      DefaultErrorKey,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Formatter returns the formatter entries are rendered with for the hook.
+func (h *Hook) Formatter() logger.Formatter {
+	return h.fmtr
+}
+
+// Enabled reports whether the context holds a recording span.
+func (h *Hook) Enabled(ctx context.Context, _ logger.Level) bool {
+	return trace.SpanFromContext(ctx).IsRecording()
+}
+
+// Fire adds the entry as an event on the recording span in the context.
+func (h *Hook) Fire(ctx context.Context, lvl logger.Level, p []byte) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	e, err := record.Decode(p)
+	if err != nil {
+		return
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(e.Fields)+1)
+	if h.severityKey != "" {
+		attrs = append(attrs, attribute.String(h.severityKey, lvl.String()))
+	}
+	errMsg := e.Msg
+	for _, f := range e.Fields {
+		if f.Key == h.errKey {
+			if s, ok := f.Value.(string); ok && s != "" {
+				errMsg = s
+			}
+		}
+		attrs = append(attrs, otelconv.Attribute(f))
+	}
+
+	opts := []trace.EventOption{trace.WithAttributes(attrs...)}
+	if !e.Time.IsZero() {
+		opts = append(opts, trace.WithTimestamp(e.Time))
+	}
+
+	span.AddEvent(e.Msg, opts...)
+
+	if h.errStatus && (lvl == logger.Error || lvl == logger.Crit) {
+		span.SetStatus(codes.Error, e.Msg)
+		span.RecordError(errors.New(errMsg))
+	}
+}
@@ -0,0 +1,155 @@
+package otelspan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/otelspan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestHook(t *testing.T) {
+	span := &testSpan{recording: true}
+	c := trace.ContextWithSpan(context.Background(), span)
+
+	log := logger.New(discard{}, logger.LogfmtFormat(), logger.Info).
+		WithHook(otelspan.NewHook()).
+		With(ctx.Str("svc", "api"))
+	c = logger.WithContext(c, log, ctx.Int("req", 1))
+
+	log.Ctx(c).Info("some message", ctx.Bool("bool", true))
+	log.Ctx(c).Error("some error", ctx.Str("error", "boom"))
+
+	require.Len(t, span.events, 2)
+	assert.Equal(t, "some message", span.events[0].name)
+	want := []attribute.KeyValue{
+		attribute.String("log.severity", "info"),
+		attribute.String("svc", "api"),
+		attribute.Int64("req", 1),
+		attribute.Bool("bool", true),
+	}
+	assert.Equal(t, want, span.events[0].attrs)
+	assert.Equal(t, "some error", span.events[1].name)
+	assert.Equal(t, codes.Unset, span.code)
+	assert.Empty(t, span.errs)
+}
+
+func TestHook_WithErrorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       func(log *logger.Logger)
+		wantCode codes.Code
+		wantDesc string
+		wantErrs []string
+	}{
+		{
+			name:     "info",
+			fn:       func(log *logger.Logger) { log.Info("some message") },
+			wantCode: codes.Unset,
+		},
+		{
+			name:     "error with error field",
+			fn:       func(log *logger.Logger) { log.Error("some error", ctx.Str("error", "boom")) },
+			wantCode: codes.Error,
+			wantDesc: "some error",
+			wantErrs: []string{"boom"},
+		},
+		{
+			name:     "crit without error field",
+			fn:       func(log *logger.Logger) { log.Crit("some crit") },
+			wantCode: codes.Error,
+			wantDesc: "some crit",
+			wantErrs: []string{"some crit"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			span := &testSpan{recording: true}
+			c := trace.ContextWithSpan(context.Background(), span)
+
+			log := logger.New(discard{}, logger.LogfmtFormat(), logger.Info).
+				WithHook(otelspan.NewHook(otelspan.WithErrorStatus()))
+
+			test.fn(log.Ctx(c))
+
+			require.Len(t, span.events, 1)
+			assert.Equal(t, test.wantCode, span.code)
+			assert.Equal(t, test.wantDesc, span.desc)
+			assert.Equal(t, test.wantErrs, span.errs)
+		})
+	}
+}
+
+func TestHook_WithSeverityKey(t *testing.T) {
+	span := &testSpan{recording: true}
+	c := trace.ContextWithSpan(context.Background(), span)
+
+	log := logger.New(discard{}, logger.LogfmtFormat(), logger.Info).
+		WithHook(otelspan.NewHook(otelspan.WithSeverityKey("")))
+
+	log.Ctx(c).Info("some message", ctx.Str("str", "string"))
+
+	require.Len(t, span.events, 1)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("str", "string")}, span.events[0].attrs)
+}
+
+func TestHook_IgnoresNonRecordingSpan(t *testing.T) {
+	span := &testSpan{}
+	c := trace.ContextWithSpan(context.Background(), span)
+
+	log := logger.New(discard{}, logger.LogfmtFormat(), logger.Info).
+		WithHook(otelspan.NewHook(otelspan.WithErrorStatus()))
+
+	log.Ctx(c).Error("some error")
+	log.Info("no context")
+
+	assert.Empty(t, span.events)
+	assert.Equal(t, codes.Unset, span.code)
+}
+
+type event struct {
+	name  string
+	attrs []attribute.KeyValue
+}
+
+type testSpan struct {
+	noop.Span
+
+	recording bool
+	events    []event
+	code      codes.Code
+	desc      string
+	errs      []string
+}
+
+func (s *testSpan) IsRecording() bool {
+	return s.recording
+}
+
+func (s *testSpan) AddEvent(name string, opts ...trace.EventOption) {
+	cfg := trace.NewEventConfig(opts...)
+	s.events = append(s.events, event{name: name, attrs: cfg.Attributes()})
+}
+
+func (s *testSpan) SetStatus(code codes.Code, desc string) {
+	s.code = code
+	s.desc = desc
+}
+
+func (s *testSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.errs = append(s.errs, err.Error())
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,589 @@
+// Package parser implements parsing of entries written by the json and
+// logfmt formatters back into records.
+//
+//	s := parser.NewScanner(os.Stdin)
+//	for s.Scan() {
+//		rec, err := s.Record()
+//		if err != nil {
+//			// Handle the unparsable line.
+//			continue
+//		}
+//		fmt.Println(rec.Level, rec.Msg)
+//	}
+//	if err := s.Err(); err != nil {
+//		// Handle error.
+//	}
+//
+// Field values are typed from their text, as the formats do not record
+// types: numbers are decoded as int64, uint64 or float64, strings holding
+// a duration or a time in the time format as time.Duration or time.Time,
+// and comma-joined logfmt values as []any. A string field holding such
+// text is therefore decoded with the type it resembles. Logfmt arrays with
+// quoted elements are quoted as a whole, escaping the elements.
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hamba/logger/v2"
+)
+
+// Format is the format of a line.
+type Format int
+
+// Formats.
+const (
+	// FormatAuto detects the format of each line.
+	FormatAuto Format = iota
+	FormatJSON
+	FormatLogfmt
+)
+
+// Errors returned for lines missing required fields.
+var (
+	ErrMissingLevel   = errors.New("parser: missing level")
+	ErrMissingMessage = errors.New("parser: missing message")
+)
+
+// SyntaxError is returned for lines that are not valid in their format.
+type SyntaxError struct {
+	// Offset is the byte offset in the line the error occurred at.
+	Offset int
+	Msg    string
+}
+
+// Error returns the error message.
+func (e *SyntaxError) Error() string {
+	return "parser: " + e.Msg + " at offset " + strconv.Itoa(e.Offset)
+}
+
+// Field is a parsed context field.
+//
+// The value is one of string, bool, int64, uint64, float64, time.Time,
+// time.Duration, []any, map[string]any or nil. Nested json objects are
+// flattened into dotted keys, unless they are held in an array.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Record is a parsed log entry.
+type Record struct {
+	Time   time.Time
+	Level  logger.Level
+	Msg    string
+	Fields []Field
+}
+
+// Field returns the value of the last field with the given key.
+func (r Record) Field(key string) (any, bool) {
+	for i := len(r.Fields) - 1; i >= 0; i-- {
+		if r.Fields[i].Key == key {
+			return r.Fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Option configures a parser.
+type Option func(*Parser)
+
+// WithFormat sets the format lines are parsed in.
+func WithFormat(f Format) Option {
+	return func(p *Parser) {
+		p.format = f
+	}
+}
+
+// WithTimeFormat sets the layout times are parsed with. It defaults to
+// logger.TimeFormat at the time the parser is created.
+func WithTimeFormat(layout string) Option {
+	return WithTimeFormats(layout)
+}
+
+// WithTimeFormats sets the layouts times are parsed with, trying each
+// in order. Empty layouts are ignored.
+func WithTimeFormats(layouts ...string) Option {
+	return func(p *Parser) {
+		p.timeFormats = slices.DeleteFunc(slices.Clone(layouts), func(l string) bool { return l == "" })
+	}
+}
+
+// TimeFormats returns the layouts to parse times with: the layout if it is
+// not empty, otherwise the layouts of the time formats written by the
+// logger, which are detected by trying each in order.
+func TimeFormats(layout string) []string {
+	if layout != "" {
+		return []string{layout}
+	}
+	return []string{time.RFC3339Nano, logger.TimeFormatISO8601}
+}
+
+// Parser parses lines into records.
+type Parser struct {
+	format      Format
+	timeFormats []string
+}
+
+// New returns a parser.
+func New(opts ...Option) *Parser {
+	p := &Parser{}
+	WithTimeFormat(logger.TimeFormat)(p)
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Parse parses a line in the json or logfmt format, detecting the format.
+func Parse(line []byte) (Record, error) {
+	return New().Parse(line)
+}
+
+// Detect returns the format of a line.
+func Detect(line []byte) Format {
+	line = bytes.TrimLeft(line, " \t")
+	if len(line) > 0 && line[0] == '{' {
+		return FormatJSON
+	}
+	return FormatLogfmt
+}
+
+// Parse parses a line into a record.
+func (p *Parser) Parse(line []byte) (Record, error) {
+	line = bytes.TrimSpace(line)
+
+	format := p.format
+	if format == FormatAuto {
+		format = Detect(line)
+	}
+
+	var (
+		fields []Field
+		err    error
+	)
+	switch format {
+	case FormatJSON:
+		fields, err = p.parseJSON(line)
+	default:
+		fields, err = p.parseLogfmt(line)
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	return p.record(fields)
+}
+
+// record moves the first timestamp, level and message fields into a record.
+func (p *Parser) record(fields []Field) (Record, error) {
+	var (
+		rec             Record
+		hasTS, hasLevel bool
+		hasMsg          bool
+	)
+	rec.Fields = fields[:0]
+	for _, f := range fields {
+		switch {
+		case f.Key == logger.TimestampKey && !hasTS:
+			ts, ok := p.timestamp(f.Value)
+			if !ok {
+				break
+			}
+			rec.Time, hasTS = ts, true
+			continue
+		case f.Key == logger.LevelKey && !hasLevel:
+			s, ok := f.Value.(string)
+			if !ok {
+				return Record{}, ErrMissingLevel
+			}
+			lvl, err := logger.LevelFromString(s)
+			if err != nil {
+				return Record{}, fmt.Errorf("parser: %w", err)
+			}
+			rec.Level, hasLevel = lvl, true
+			continue
+		case f.Key == logger.MessageKey && !hasMsg:
+			rec.Msg, hasMsg = toString(f.Value), true
+			continue
+		}
+		rec.Fields = append(rec.Fields, f)
+	}
+
+	if !hasLevel {
+		return Record{}, ErrMissingLevel
+	}
+	if !hasMsg {
+		return Record{}, ErrMissingMessage
+	}
+	if len(rec.Fields) == 0 {
+		rec.Fields = nil
+	}
+	return rec, nil
+}
+
+func (p *Parser) timestamp(v any) (time.Time, bool) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, true
+	case int64:
+		return time.Unix(v, 0), true
+	case uint64:
+		return time.Unix(int64(v), 0), true //nolint:gosec // Unix times are within range.
+	case string:
+		return p.parseTime(v)
+	}
+	return time.Time{}, false
+}
+
+// parseTime parses a time in the first matching time format.
+func (p *Parser) parseTime(s string) (time.Time, bool) {
+	for _, layout := range p.timeFormats {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (p *Parser) parseJSON(line []byte) ([]Field, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, &SyntaxError{Offset: 0, Msg: "expected object"}
+	}
+
+	fields, err := p.parseJSONObject(dec, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, &SyntaxError{Offset: int(dec.InputOffset()), Msg: "unexpected data after object"}
+	}
+	return fields, nil
+}
+
+// parseJSONObject parses the members of an object, flattening nested
+// objects into dotted keys.
+func (p *Parser) parseJSONObject(dec *json.Decoder, prefix string, fields []Field) ([]Field, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, jsonError(dec, err)
+		}
+		if tok == json.Delim('}') {
+			return fields, nil
+		}
+
+		key, ok := tok.(string)
+		if !ok {
+			return nil, &SyntaxError{Offset: int(dec.InputOffset()), Msg: "expected key"}
+		}
+		key = prefix + key
+
+		tok, err = dec.Token()
+		if err != nil {
+			return nil, jsonError(dec, err)
+		}
+		if tok == json.Delim('{') {
+			fields, err = p.parseJSONObject(dec, key+".", fields)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		val, err := p.parseJSONValue(dec, tok, isRaw(key))
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field{Key: key, Value: val})
+	}
+}
+
+func (p *Parser) parseJSONValue(dec *json.Decoder, tok json.Token, raw bool) (any, error) {
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '[':
+			vals := []any{}
+			for {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, jsonError(dec, err)
+				}
+				if tok == json.Delim(']') {
+					return vals, nil
+				}
+
+				val, err := p.parseJSONValue(dec, tok, false)
+				if err != nil {
+					return nil, err
+				}
+				vals = append(vals, val)
+			}
+		case '{':
+			fields, err := p.parseJSONObject(dec, "", nil)
+			if err != nil {
+				return nil, err
+			}
+			m := make(map[string]any, len(fields))
+			for _, f := range fields {
+				m[f.Key] = f.Value
+			}
+			return m, nil
+		}
+		return nil, &SyntaxError{Offset: int(dec.InputOffset()), Msg: "unexpected " + tok.String()}
+	case json.Number:
+		return number(string(tok))
+	case string:
+		if raw {
+			return tok, nil
+		}
+		return p.stringValue(tok), nil
+	default:
+		// Bools and null.
+		return tok, nil
+	}
+}
+
+func jsonError(dec *json.Decoder, err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return &SyntaxError{Offset: int(dec.InputOffset()), Msg: err.Error()}
+}
+
+func (p *Parser) parseLogfmt(line []byte) ([]Field, error) {
+	var fields []Field
+
+	i := 0
+	for i < len(line) {
+		if line[i] == ' ' {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' && line[i] != '"' {
+			i++
+		}
+		if i == len(line) || line[i] != '=' || i == start {
+			return nil, &SyntaxError{Offset: i, Msg: "expected key"}
+		}
+		key := string(line[start:i])
+		i++
+
+		var (
+			val any
+			err error
+		)
+		if isRaw(key) {
+			val, i, err = parseLogfmtString(line, i)
+		} else {
+			val, i, err = p.parseLogfmtValue(line, i)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, Field{Key: key, Value: val})
+	}
+	return fields, nil
+}
+
+// parseLogfmtString parses the value starting at i as a string.
+func parseLogfmtString(line []byte, i int) (string, int, error) {
+	if i < len(line) && line[i] == '"' {
+		return parseQuoted(line, i)
+	}
+
+	start := i
+	for i < len(line) && line[i] != ' ' {
+		i++
+	}
+	return string(line[start:i]), i, nil
+}
+
+// parseLogfmtValue parses the value starting at i, returning the value
+// and the offset after it. Comma-joined values are parsed as an array.
+func (p *Parser) parseLogfmtValue(line []byte, i int) (any, int, error) {
+	var vals []any
+	for {
+		var (
+			val any
+			err error
+		)
+		if i < len(line) && line[i] == '"' {
+			var str string
+			str, i, err = parseQuoted(line, i)
+			if err != nil {
+				return nil, 0, err
+			}
+			val = p.stringValue(str)
+			if arr, ok := p.quotedArray(str); ok && vals == nil && (i == len(line) || line[i] == ' ') {
+				val = arr
+			}
+		} else {
+			start := i
+			for i < len(line) && line[i] != ' ' && line[i] != ',' {
+				if line[i] == '"' {
+					return nil, 0, &SyntaxError{Offset: i, Msg: "unexpected quote"}
+				}
+				i++
+			}
+			val = p.bareValue(string(line[start:i]), vals != nil || (i < len(line) && line[i] == ','))
+		}
+
+		if i < len(line) && line[i] == ',' {
+			vals = append(vals, val)
+			i++
+			continue
+		}
+		if i < len(line) && line[i] != ' ' {
+			return nil, 0, &SyntaxError{Offset: i, Msg: "expected space"}
+		}
+
+		if vals == nil {
+			return val, i, nil
+		}
+		return append(vals, val), i, nil
+	}
+}
+
+// quotedArray parses a quoted logfmt value holding quoted array elements,
+// as written for arrays with elements that must be quoted.
+func (p *Parser) quotedArray(s string) ([]any, bool) {
+	if strings.IndexByte(s, '"') < 0 || strings.IndexByte(s, ',') < 0 {
+		return nil, false
+	}
+
+	v, i, err := p.parseLogfmtValue([]byte(s), 0)
+	if err != nil || i != len(s) {
+		return nil, false
+	}
+	arr, ok := v.([]any)
+	return arr, ok
+}
+
+func parseQuoted(line []byte, i int) (string, int, error) {
+	start := i
+	i++
+	for i < len(line) && line[i] != '"' {
+		if line[i] == '\\' {
+			i++
+		}
+		i++
+	}
+	if i >= len(line) {
+		return "", 0, &SyntaxError{Offset: start, Msg: "unterminated string"}
+	}
+	i++
+
+	s, err := strconv.Unquote(string(line[start:i]))
+	if err != nil {
+		return "", 0, &SyntaxError{Offset: start, Msg: "invalid string"}
+	}
+	if i < len(line) && line[i] != ' ' && line[i] != ',' {
+		return "", 0, &SyntaxError{Offset: i, Msg: "expected space"}
+	}
+	return s, i, nil
+}
+
+// bareValue types an unquoted logfmt value. Empty values are nil, unless
+// they are an element of an array, as is null.
+func (p *Parser) bareValue(s string, elem bool) any {
+	switch s {
+	case "":
+		if elem {
+			return ""
+		}
+		return nil
+	case "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if c := s[0]; c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.' || s == "NaN" {
+		if v, err := number(s); err == nil {
+			return v
+		}
+	}
+	return p.stringValue(s)
+}
+
+// stringValue types a string holding a duration, a time or a non-finite
+// float.
+func (p *Parser) stringValue(s string) any {
+	switch s {
+	case "NaN", "+Inf", "-Inf":
+		v, _ := strconv.ParseFloat(s, 64)
+		return v
+	}
+
+	if isDuration(s) {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d
+		}
+	}
+	if t, ok := p.parseTime(s); ok {
+		return t
+	}
+	return s
+}
+
+func number(s string) (any, error) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, &SyntaxError{Msg: "invalid number " + strconv.Quote(s)}
+	}
+	return f, nil
+}
+
+// isDuration reports whether s looks like a formatted time.Duration.
+func isDuration(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	if c := s[0]; (c < '0' || c > '9') && c != '-' {
+		return false
+	}
+	last := s[len(s)-1]
+	return last == 's' || last == 'm' || last == 'h'
+}
+
+// isRaw reports whether the values of the key are never typed.
+func isRaw(key string) bool {
+	return key == logger.LevelKey || key == logger.MessageKey
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
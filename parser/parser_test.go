@@ -0,0 +1,420 @@
+package parser_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/hamba/logger/v2/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		fmtr logger.Formatter
+	}{
+		{
+			name: "json",
+			fmtr: logger.JSONFormat(),
+		},
+		{
+			name: "logfmt",
+			fmtr: logger.LogfmtFormat(logger.WithFloatPrecision(-1)),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			log := logger.New(&buf, test.fmtr, logger.Info).With(ctx.Str("svc", "api"))
+
+			log.Warn("some message",
+				ctx.Str("str", "some string"),
+				ctx.Str("escaped", "a \"b\"\\c\nd\te\x01"),
+				ctx.Strs("strs", []string{"a", "b c"}),
+				ctx.Bytes("bytes", []byte{1, 2}),
+				ctx.Bool("bool", true),
+				ctx.Int("int", -1),
+				ctx.Uint64("uint", math.MaxUint64),
+				ctx.Float64("float", 4.56),
+				ctx.Duration("dur", 1500*time.Millisecond),
+			)
+
+			got, err := parser.Parse(buf.Bytes())
+
+			require.NoError(t, err)
+			want := parser.Record{
+				Level: logger.Warn,
+				Msg:   "some message",
+				Fields: []parser.Field{
+					{Key: "svc", Value: "api"},
+					{Key: "str", Value: "some string"},
+					{Key: "escaped", Value: "a \"b\"\\c\nd\te\x01"},
+					{Key: "strs", Value: []any{"a", "b c"}},
+					{Key: "bytes", Value: []any{int64(1), int64(2)}},
+					{Key: "bool", Value: true},
+					{Key: "int", Value: int64(-1)},
+					{Key: "uint", Value: uint64(math.MaxUint64)},
+					{Key: "float", Value: 4.56},
+					{Key: "dur", Value: 1500 * time.Millisecond},
+				},
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestParse_Timestamp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		line string
+	}{
+		{
+			name: "json",
+			line: `{"ts":1609459200,"lvl":"info","msg":"some message"}`,
+		},
+		{
+			name: "logfmt",
+			line: `ts=1609459200 lvl=info msg="some message"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parser.New(parser.WithTimeFormat(logger.TimeFormatUnix)).Parse([]byte(test.line))
+
+			require.NoError(t, err)
+			assert.True(t, got.Time.Equal(time.Unix(1609459200, 0)))
+			assert.Equal(t, logger.Info, got.Level)
+			assert.Equal(t, "some message", got.Msg)
+			assert.Nil(t, got.Fields)
+		})
+	}
+}
+
+func TestParse_TimeFormat(t *testing.T) {
+	logger.TimeFormat = logger.TimeFormatISO8601
+	t.Cleanup(func() { logger.TimeFormat = logger.TimeFormatUnix })
+
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		fmtr logger.Formatter
+	}{
+		{
+			name: "json",
+			fmtr: logger.JSONFormat(),
+		},
+		{
+			name: "logfmt",
+			fmtr: logger.LogfmtFormat(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, test.fmtr, logger.Info)
+
+			log.Info("some message", ctx.Time("time", ts), ctx.Int("int", 1))
+
+			got, err := parser.New().Parse(buf.Bytes())
+
+			require.NoError(t, err)
+			require.Len(t, got.Fields, 2)
+			assert.Equal(t, "time", got.Fields[0].Key)
+			assert.True(t, ts.Equal(got.Fields[0].Value.(time.Time)))
+			assert.Equal(t, parser.Field{Key: "int", Value: int64(1)}, got.Fields[1])
+		})
+	}
+}
+
+func TestParse_TimeFormats(t *testing.T) {
+	t.Parallel()
+
+	p := parser.New(parser.WithTimeFormats(time.RFC3339Nano, logger.TimeFormatISO8601))
+
+	got, err := p.Parse([]byte(`ts=2021-01-02T03:04:05+0000 lvl=info msg=m a=2021-01-02T03:04:05.5Z`))
+
+	require.NoError(t, err)
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.True(t, ts.Equal(got.Time))
+	require.Len(t, got.Fields, 1)
+	assert.True(t, ts.Add(500*time.Millisecond).Equal(got.Fields[0].Value.(time.Time)))
+}
+
+func TestTimeFormats(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{time.RFC3339Nano, logger.TimeFormatISO8601}, parser.TimeFormats(""))
+	assert.Equal(t, []string{time.Kitchen}, parser.TimeFormats(time.Kitchen))
+}
+
+func TestParse_Values(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		line string
+		want any
+	}{
+		{
+			name: "logfmt empty",
+			line: `lvl=info msg=m key=`,
+			want: nil,
+		},
+		{
+			name: "logfmt quoted empty",
+			line: `lvl=info msg=m key=""`,
+			want: "",
+		},
+		{
+			name: "logfmt array with empty element",
+			line: `lvl=info msg=m key=a,,""`,
+			want: []any{"a", "", ""},
+		},
+		{
+			name: "logfmt quoted elements",
+			line: `lvl=info msg=m key=a,"b c"`,
+			want: []any{"a", "b c"},
+		},
+		{
+			name: "logfmt quoted array",
+			line: `lvl=info msg=m key="\"a,b\",\"c d\",1"`,
+			want: []any{"a,b", "c d", int64(1)},
+		},
+		{
+			name: "logfmt quoted comma",
+			line: `lvl=info msg=m key="a,b"`,
+			want: "a,b",
+		},
+		{
+			name: "logfmt unicode escape",
+			line: `lvl=info msg=m key="a\u007f\ufffd"`,
+			want: "a\x7f\ufffd",
+		},
+		{
+			name: "logfmt non-finite float",
+			line: `lvl=info msg=m key=+Inf`,
+			want: math.Inf(1),
+		},
+		{
+			name: "logfmt null",
+			line: `lvl=info msg=m key=null`,
+			want: nil,
+		},
+		{
+			name: "logfmt quoted null",
+			line: `lvl=info msg=m key="null"`,
+			want: "null",
+		},
+		{
+			name: "logfmt zero is not a duration",
+			line: `lvl=info msg=m key=0`,
+			want: int64(0),
+		},
+		{
+			name: "logfmt micro seconds",
+			line: `lvl=info msg=m key=1.5µs`,
+			want: 1500 * time.Nanosecond,
+		},
+		{
+			name: "json null",
+			line: `{"lvl":"info","msg":"m","key":null}`,
+			want: nil,
+		},
+		{
+			name: "json non-finite float",
+			line: `{"lvl":"info","msg":"m","key":"-Inf"}`,
+			want: math.Inf(-1),
+		},
+		{
+			name: "json exponent float",
+			line: `{"lvl":"info","msg":"m","key":1e+21}`,
+			want: 1e21,
+		},
+		{
+			name: "json array of objects",
+			line: `{"lvl":"info","msg":"m","key":[{"a":1}]}`,
+			want: []any{map[string]any{"a": int64(1)}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parser.Parse([]byte(test.line))
+
+			require.NoError(t, err)
+			assert.Equal(t, []parser.Field{{Key: "key", Value: test.want}}, got.Fields)
+		})
+	}
+}
+
+func TestParse_NonFiniteNull(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(logger.WithNonFinite(logger.NonFiniteNull)), logger.Info)
+
+	log.Info("some message", ctx.Float64("nan", math.NaN()), ctx.Str("str", "null"))
+
+	got, err := parser.Parse(buf.Bytes())
+
+	require.NoError(t, err)
+	want := []parser.Field{{Key: "nan", Value: nil}, {Key: "str", Value: "null"}}
+	assert.Equal(t, want, got.Fields)
+}
+
+func TestParse_MessageIsNotTyped(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		line string
+	}{
+		{
+			name: "json",
+			line: `{"lvl":"info","msg":"1s"}`,
+		},
+		{
+			name: "logfmt",
+			line: `lvl=info msg=1s`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parser.Parse([]byte(test.line))
+
+			require.NoError(t, err)
+			assert.Equal(t, "1s", got.Msg)
+		})
+	}
+}
+
+func TestParse_FlattensNestedObjects(t *testing.T) {
+	t.Parallel()
+
+	got, err := parser.Parse([]byte(`{"lvl":"info","msg":"m","http":{"method":"GET","response":{"status":200}}}`))
+
+	require.NoError(t, err)
+	want := []parser.Field{
+		{Key: "http.method", Value: "GET"},
+		{Key: "http.response.status", Value: int64(200)},
+	}
+	assert.Equal(t, want, got.Fields)
+}
+
+func TestParse_WithFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := parser.New(parser.WithFormat(parser.FormatLogfmt)).Parse([]byte(`{"lvl":"info","msg":"m"}`))
+
+	var synErr *parser.SyntaxError
+	assert.ErrorAs(t, err, &synErr)
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		line    string
+		wantErr error
+	}{
+		{
+			name:    "logfmt missing level",
+			line:    `msg=m`,
+			wantErr: parser.ErrMissingLevel,
+		},
+		{
+			name:    "json missing message",
+			line:    `{"lvl":"info"}`,
+			wantErr: parser.ErrMissingMessage,
+		},
+		{
+			name: "unknown level",
+			line: `lvl=bad msg=m`,
+		},
+		{
+			name: "logfmt missing equals",
+			line: `lvl=info msg=m key`,
+		},
+		{
+			name: "logfmt unterminated string",
+			line: `lvl=info msg="m`,
+		},
+		{
+			name: "logfmt data after string",
+			line: `lvl=info msg="m"x`,
+		},
+		{
+			name: "logfmt bare quote",
+			line: `lvl=info msg=m key=a"b`,
+		},
+		{
+			name: "plain text",
+			line: `some text`,
+		},
+		{
+			name: "json truncated",
+			line: `{"lvl":"info","msg":"m"`,
+		},
+		{
+			name: "json trailing data",
+			line: `{"lvl":"info","msg":"m"} x`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parser.Parse([]byte(test.line))
+
+			require.Error(t, err)
+			if test.wantErr != nil {
+				assert.True(t, errors.Is(err, test.wantErr))
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, parser.FormatJSON, parser.Detect([]byte(` {"lvl":"info"}`)))
+	assert.Equal(t, parser.FormatLogfmt, parser.Detect([]byte(`lvl=info`)))
+}
+
+func TestRecord_Field(t *testing.T) {
+	t.Parallel()
+
+	rec := parser.Record{Fields: []parser.Field{{Key: "a", Value: 1}, {Key: "a", Value: 2}}}
+
+	got, ok := rec.Field("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, got)
+
+	_, ok = rec.Field("b")
+	assert.False(t, ok)
+}
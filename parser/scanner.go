@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// MaxLineSize is the default maximum size of a line read by a Scanner.
+const MaxLineSize = 1 << 20
+
+// LineError is an error parsing a line read by a Scanner.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+// Error returns the error message.
+func (e *LineError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the parse error.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Scanner reads records from lines in a stream, skipping blank lines.
+//
+// Lines that cannot be parsed do not stop the scanner, their error is
+// returned by Record, allowing them to be handled or passed through.
+type Scanner struct {
+	s    *bufio.Scanner
+	p    *Parser
+	line int
+
+	rec Record
+	err error
+}
+
+// NewScanner returns a scanner reading records from r.
+func NewScanner(r io.Reader, opts ...Option) *Scanner {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, MaxLineSize)
+
+	return &Scanner{
+		s: s,
+		p: New(opts...),
+	}
+}
+
+// Buffer sets the initial buffer and the maximum size of a line.
+// It must be called before the first call to Scan.
+func (s *Scanner) Buffer(buf []byte, maxSize int) {
+	s.s.Buffer(buf, maxSize)
+}
+
+// Scan advances the scanner to the next line, returning false when the
+// end of the stream is reached or an error occurs reading it.
+func (s *Scanner) Scan() bool {
+	for s.s.Scan() {
+		s.line++
+		if len(bytes.TrimSpace(s.s.Bytes())) == 0 {
+			continue
+		}
+
+		s.rec, s.err = s.p.Parse(s.s.Bytes())
+		if s.err != nil {
+			s.err = &LineError{Line: s.line, Err: s.err}
+		}
+		return true
+	}
+	return false
+}
+
+// Record returns the record parsed from the current line, or the
+// error parsing it.
+func (s *Scanner) Record() (Record, error) {
+	return s.rec, s.err
+}
+
+// Bytes returns the current line. The underlying array may be overwritten
+// by the next call to Scan.
+func (s *Scanner) Bytes() []byte {
+	return s.s.Bytes()
+}
+
+// Line returns the number of the current line.
+func (s *Scanner) Line() int {
+	return s.line
+}
+
+// Err returns the first error reading the stream.
+func (s *Scanner) Err() error {
+	return s.s.Err()
+}
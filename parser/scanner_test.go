@@ -0,0 +1,69 @@
+package parser_test
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanner(t *testing.T) {
+	t.Parallel()
+
+	in := "lvl=info msg=first\n\n{\"lvl\":\"eror\",\"msg\":\"second\"}\nnot a log line\r\nlvl=dbug msg=third"
+	s := parser.NewScanner(strings.NewReader(in))
+
+	var (
+		msgs  []string
+		lines []int
+		raw   []string
+	)
+	for s.Scan() {
+		rec, err := s.Record()
+		if err != nil {
+			var lineErr *parser.LineError
+			require.ErrorAs(t, err, &lineErr)
+			assert.Equal(t, 4, lineErr.Line)
+			raw = append(raw, string(s.Bytes()))
+			continue
+		}
+		msgs = append(msgs, rec.Msg)
+		lines = append(lines, s.Line())
+	}
+
+	require.NoError(t, s.Err())
+	assert.Equal(t, []string{"first", "second", "third"}, msgs)
+	assert.Equal(t, []int{1, 3, 5}, lines)
+	assert.Equal(t, []string{"not a log line"}, raw)
+}
+
+func TestScanner_Levels(t *testing.T) {
+	t.Parallel()
+
+	s := parser.NewScanner(strings.NewReader("lvl=crit msg=a\nlvl=trce msg=b\n"), parser.WithFormat(parser.FormatLogfmt))
+
+	var lvls []logger.Level
+	for s.Scan() {
+		rec, err := s.Record()
+		require.NoError(t, err)
+		lvls = append(lvls, rec.Level)
+	}
+
+	require.NoError(t, s.Err())
+	assert.Equal(t, []logger.Level{logger.Crit, logger.Trace}, lvls)
+}
+
+func TestScanner_Buffer(t *testing.T) {
+	t.Parallel()
+
+	s := parser.NewScanner(strings.NewReader("lvl=info msg=" + strings.Repeat("a", 100)))
+	s.Buffer(nil, 64)
+
+	assert.False(t, s.Scan())
+	assert.True(t, errors.Is(s.Err(), bufio.ErrTooLong))
+}
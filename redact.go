@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Redacted replaces masked values.
+const Redacted = "[REDACTED]"
+
+// Common patterns of sensitive values.
+var (
+	CreditCardPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	JWTPattern        = regexp.MustCompile(`\beyJ[\w-]*\.eyJ[\w-]*\.[\w-]*`)
+	EmailPattern      = regexp.MustCompile(`[\w.%+-]+@[\w-]+(?:\.[\w-]+)*\.[a-zA-Z]{2,}`)
+)
+
+// MaskStyle is the way a sensitive value is masked.
+type MaskStyle int
+
+// Mask styles.
+const (
+	// MaskFull replaces the value with [REDACTED].
+	MaskFull MaskStyle = iota
+	// MaskHash replaces the value with a stable short hash, allowing
+	// values to be correlated without being revealed.
+	MaskHash
+	// MaskPartial reveals the last 4 characters of values of at least
+	// 8 characters, replacing shorter values with [REDACTED].
+	MaskPartial
+)
+
+func (m MaskStyle) mask(s string) string {
+	switch m {
+	case MaskHash:
+		sum := sha256.Sum256([]byte(s))
+		return fmt.Sprintf("sha256:%x", sum[:6])
+	case MaskPartial:
+		n := utf8.RuneCountInString(s)
+		if n < 8 {
+			return Redacted
+		}
+		i := len(s)
+		for range 4 {
+			_, size := utf8.DecodeLastRuneInString(s[:i])
+			i -= size
+		}
+		return "****" + s[i:]
+	default:
+		return Redacted
+	}
+}
+
+// RedactOption configures a Redactor.
+type RedactOption func(*Redactor)
+
+// RedactKeys masks the values of fields with the given keys. Keys match
+// case-insensitively and may be glob patterns, e.g. "*_token".
+func RedactKeys(keys ...string) RedactOption {
+	return func(r *Redactor) {
+		for _, k := range keys {
+			k = strings.ToLower(k)
+			if strings.ContainsAny(k, `*?[\`) {
+				r.globs = append(r.globs, k)
+				continue
+			}
+			r.keys = append(r.keys, k)
+		}
+	}
+}
+
+// RedactValues masks the parts of string values matching the patterns.
+func RedactValues(patterns ...*regexp.Regexp) RedactOption {
+	return func(r *Redactor) {
+		r.patterns = append(r.patterns, patterns...)
+	}
+}
+
+// WithMaskStyle sets the way values are masked.
+func WithMaskStyle(style MaskStyle) RedactOption {
+	return func(r *Redactor) {
+		r.style = style
+	}
+}
+
+// Redactor masks sensitive field values as they are appended to an event.
+//
+// Values of fields with matching keys are masked entirely, whatever their
+// type, while string values are masked where they match a pattern.
+type Redactor struct {
+	keys     []string
+	globs    []string
+	patterns []*regexp.Regexp
+	style    MaskStyle
+}
+
+// NewRedactor returns a redactor.
+func NewRedactor(opts ...RedactOption) *Redactor {
+	r := &Redactor{}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// WithRedactor returns a new Logger masking field values with the redactor.
+//
+// Fields already added to the logger are rendered again with the redactor.
+func (l *Logger) WithRedactor(r *Redactor) *Logger {
+	log := l.withCtx(nil, nil)
+	log.redact = r
+	log.rerender()
+	return log
+}
+
+// matchKey reports whether the values of the key should be masked.
+func (r *Redactor) matchKey(k string) bool {
+	if len(r.keys) == 0 && len(r.globs) == 0 {
+		return false
+	}
+
+	for _, key := range r.keys {
+		if strings.EqualFold(key, k) {
+			return true
+		}
+	}
+	if len(r.globs) == 0 {
+		return false
+	}
+
+	k = strings.ToLower(k)
+	for _, glob := range r.globs {
+		if ok, _ := path.Match(glob, k); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// String masks the parts of the string matching a pattern.
+func (r *Redactor) String(s string) string {
+	for _, p := range r.patterns {
+		// Matching first avoids allocating when nothing is masked.
+		if p.MatchString(s) {
+			s = p.ReplaceAllStringFunc(s, r.style.mask)
+		}
+	}
+	return s
+}
+
+// value masks a value of a field with a matching key.
+func (r *Redactor) value(v any) string {
+	if r.style == MaskFull {
+		return Redacted
+	}
+
+	switch v := v.(type) {
+	case string:
+		return r.style.mask(v)
+	case time.Time:
+		return r.style.mask(v.Format(time.RFC3339Nano))
+	default:
+		return r.style.mask(fmt.Sprintf("%+v", v))
+	}
+}
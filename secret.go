@@ -0,0 +1,250 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SecretMask is the way Secret values are rendered. MaskPartial is
+// rendered as MaskFull, as secrets must never be revealed.
+var SecretMask = MaskFull
+
+// Secret is a string that is never rendered in clear.
+//
+// Secrets are rendered as [REDACTED], or a stable short hash depending
+// on SecretMask, when formatted with fmt, marshalled or logged, including
+// when held in values logged with AppendInterface.
+type Secret string
+
+// String returns the masked secret.
+func (s Secret) String() string {
+	if SecretMask == MaskHash {
+		return MaskHash.mask(string(s))
+	}
+	return Redacted
+}
+
+// GoString returns the masked secret.
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// Format writes the masked secret for all verbs.
+func (s Secret) Format(f fmt.State, _ rune) {
+	_, _ = io.WriteString(f, s.String())
+}
+
+// MarshalText returns the masked secret.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+var secretType = reflect.TypeFor[Secret]()
+
+// hiddenSecrets caches whether values of a type may hold secrets fmt
+// cannot mask, as they are only reached through unexported fields.
+var hiddenSecrets sync.Map
+
+type hiddenSecretsKey struct {
+	typ        reflect.Type
+	unexported bool
+}
+
+// formatSecrets formats v as fmt would with the "%+v" verb, masking any
+// secrets held in unexported fields. It returns false if v holds no such
+// secrets, in which case fmt masks any secrets itself.
+func formatSecrets(v any) (string, bool) {
+	val := reflect.ValueOf(v)
+	if !val.IsValid() || formatsItself(v) || !valueHidesSecret(val, 0) {
+		return "", false
+	}
+
+	var sb strings.Builder
+	writeSecretValue(&sb, val, 0)
+	return sb.String(), true
+}
+
+// formatsItself reports whether fmt formats v with one of its methods
+// rather than by walking its fields.
+func formatsItself(v any) bool {
+	switch v.(type) {
+	case fmt.Formatter, error, fmt.Stringer:
+		return true
+	default:
+		return false
+	}
+}
+
+// mayHideSecret reports whether values of the type may hold secrets fmt
+// cannot mask, the type being reached through an unexported field if
+// unexported is set.
+func mayHideSecret(typ reflect.Type, unexported bool) bool {
+	key := hiddenSecretsKey{typ: typ, unexported: unexported}
+	if ok, found := hiddenSecrets.Load(key); found {
+		return ok.(bool)
+	}
+
+	ok := hidesSecret(typ, unexported, map[reflect.Type]bool{})
+	hiddenSecrets.Store(key, ok)
+	return ok
+}
+
+func hidesSecret(typ reflect.Type, unexported bool, seen map[reflect.Type]bool) bool {
+	if typ == secretType {
+		return unexported
+	}
+	if seen[typ] {
+		return false
+	}
+	seen[typ] = true
+
+	switch typ.Kind() {
+	case reflect.Interface:
+		// Interfaces may hold any value, which is only known when walked.
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return hidesSecret(typ.Elem(), unexported, seen)
+	case reflect.Map:
+		return hidesSecret(typ.Key(), unexported, seen) || hidesSecret(typ.Elem(), unexported, seen)
+	case reflect.Struct:
+		for i := range typ.NumField() {
+			f := typ.Field(i)
+			if hidesSecret(f.Type, unexported || !f.IsExported(), seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// valueHidesSecret reports whether the value holds a secret fmt cannot
+// mask, walking it as fmt does with the "%+v" verb.
+func valueHidesSecret(v reflect.Value, depth int) bool {
+	unexported := !v.CanInterface()
+	switch {
+	case v.Type() == secretType:
+		return unexported
+	case !mayHideSecret(v.Type(), unexported):
+		return false
+	case !unexported && formatsItself(v.Interface()):
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		return !v.IsNil() && valueHidesSecret(v.Elem(), depth+1)
+	case reflect.Pointer:
+		// Fmt only follows pointers at the top level.
+		return depth == 0 && !v.IsNil() && valueHidesSecret(v.Elem(), depth+1)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if valueHidesSecret(v.Field(i), depth+1) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if valueHidesSecret(v.Index(i), depth+1) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if valueHidesSecret(iter.Key(), depth+1) || valueHidesSecret(iter.Value(), depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeSecretValue writes the value in the fmt "%+v" format.
+func writeSecretValue(sb *strings.Builder, v reflect.Value, depth int) {
+	if v.Type() == secretType {
+		sb.WriteString(Secret(v.String()).String())
+		return
+	}
+	if v.Kind() == reflect.Interface {
+		// The dynamic value of a walked interface may itself be a secret.
+		if v.IsNil() {
+			sb.WriteString("<nil>")
+			return
+		}
+		writeSecretValue(sb, v.Elem(), depth+1)
+		return
+	}
+	if v.CanInterface() && formatsItself(v.Interface()) {
+		_, _ = fmt.Fprintf(sb, "%+v", v.Interface())
+		return
+	}
+	if !valueHidesSecret(v, depth) {
+		if v.CanInterface() {
+			_, _ = fmt.Fprintf(sb, "%+v", v.Interface())
+			return
+		}
+		_, _ = fmt.Fprintf(sb, "%+v", v)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		switch {
+		case v.IsNil():
+			sb.WriteString("<nil>")
+		case depth == 0:
+			sb.WriteByte('&')
+			writeSecretValue(sb, v.Elem(), depth+1)
+		default:
+			_, _ = fmt.Fprintf(sb, "0x%x", v.Pointer())
+		}
+	case reflect.Struct:
+		sb.WriteByte('{')
+		for i := range v.NumField() {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(v.Type().Field(i).Name)
+			sb.WriteByte(':')
+			writeSecretValue(sb, v.Field(i), depth+1)
+		}
+		sb.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		sb.WriteByte('[')
+		for i := range v.Len() {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			writeSecretValue(sb, v.Index(i), depth+1)
+		}
+		sb.WriteByte(']')
+	case reflect.Map:
+		type entry struct{ k, v string }
+		entries := make([]entry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var k, val strings.Builder
+			writeSecretValue(&k, iter.Key(), depth+1)
+			writeSecretValue(&val, iter.Value(), depth+1)
+			entries = append(entries, entry{k: k.String(), v: val.String()})
+		}
+		slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.k, b.k) })
+
+		sb.WriteString("map[")
+		for i, e := range entries {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(e.k)
+			sb.WriteByte(':')
+			sb.WriteString(e.v)
+		}
+		sb.WriteByte(']')
+	default:
+		_, _ = fmt.Fprintf(sb, "%+v", v)
+	}
+}
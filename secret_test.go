@@ -0,0 +1,166 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	s := logger.Secret("hunter2")
+
+	assert.Equal(t, "[REDACTED]", s.String())
+	assert.Equal(t, "[REDACTED] [REDACTED] [REDACTED] [REDACTED]", fmt.Sprintf("%s %v %q %#v", s, s, s, s))
+
+	b, err := json.Marshal(struct{ Password logger.Secret }{s})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Password":"[REDACTED]"}`, string(b))
+}
+
+func TestSecret_Hash(t *testing.T) {
+	logger.SecretMask = logger.MaskHash
+	t.Cleanup(func() { logger.SecretMask = logger.MaskFull })
+
+	assert.Equal(t, "sha256:f52fbd32b2b3", logger.Secret("hunter2").String())
+}
+
+type credentials struct {
+	User     string
+	Password logger.Secret
+	token    logger.Secret
+	extra    any
+	nested   *credentials
+	list     []logger.Secret
+	byName   map[string]logger.Secret
+}
+
+func TestLogger_Secret(t *testing.T) {
+	tests := []struct {
+		name string
+		fmtr logger.Formatter
+		want string
+	}{
+		{
+			name: "logfmt",
+			fmtr: logger.LogfmtFormat(),
+			want: `lvl=info msg="some message" password=[REDACTED] direct=[REDACTED] ` +
+				`creds="&{User:bob Password:[REDACTED] token:[REDACTED] extra:[REDACTED] nested:<nil> list:[[REDACTED]] byName:map[a:[REDACTED] b:[REDACTED]]}" ` +
+				`plain={A:a}` + "\n",
+		},
+		{
+			name: "json",
+			fmtr: logger.JSONFormat(),
+			want: `{"lvl":"info","msg":"some message","password":"[REDACTED]","direct":"[REDACTED]",` +
+				`"creds":"&{User:bob Password:[REDACTED] token:[REDACTED] extra:[REDACTED] nested:<nil> list:[[REDACTED]] byName:map[a:[REDACTED] b:[REDACTED]]}",` +
+				`"plain":"{A:a}"}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, test.fmtr, logger.Info)
+
+			creds := &credentials{
+				User:     "bob",
+				Password: "pass",
+				token:    "token",
+				extra:    logger.Secret("extra"),
+				list:     []logger.Secret{"a"},
+				byName:   map[string]logger.Secret{"b": "b", "a": "a"},
+			}
+			log.Info("some message",
+				ctx.Secret("password", "hunter2"),
+				ctx.Interface("direct", logger.Secret("hunter2")),
+				ctx.Interface("creds", creds),
+				ctx.Interface("plain", struct{ A string }{"a"}),
+			)
+
+			assert.Equal(t, test.want, buf.String())
+		})
+	}
+}
+
+type stringer struct {
+	name any
+}
+
+func (s stringer) String() string {
+	return "stringer"
+}
+
+type secretStringer struct {
+	token logger.Secret
+}
+
+func (s secretStringer) String() string {
+	return "secret stringer"
+}
+
+func TestLogger_SecretFormatsMethods(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LogfmtFormat(), logger.Info)
+
+	log.Info("some message",
+		ctx.Interface("err", fmt.Errorf("wrapped: %w", errors.New("some error"))),
+		ctx.Interface("stringer", stringer{name: "bob"}),
+		ctx.Interface("secret", secretStringer{token: "hunter2"}),
+	)
+
+	want := `lvl=info msg="some message" err="wrapped: some error" stringer=stringer secret="secret stringer"` + "\n"
+	assert.Equal(t, want, buf.String())
+}
+
+type secretHolder struct {
+	v any
+}
+
+func TestLogger_SecretInInterfaces(t *testing.T) {
+	tests := []struct {
+		name string
+		val  any
+		want string
+	}{
+		{
+			name: "unexported interface field",
+			val:  secretHolder{v: logger.Secret("hunter2")},
+			want: "{v:[REDACTED]}",
+		},
+		{
+			name: "slice of interfaces",
+			val:  []any{secretHolder{v: logger.Secret("hunter2")}},
+			want: "[{v:[REDACTED]}]",
+		},
+		{
+			name: "map of interfaces",
+			val:  map[string]any{"x": secretHolder{v: logger.Secret("hunter2")}},
+			want: "map[x:{v:[REDACTED]}]",
+		},
+		{
+			name: "interfaces without secrets",
+			val:  []any{secretHolder{v: "a"}, 1},
+			want: "[{v:a} 1]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.JSONFormat(), logger.Info)
+
+			log.Info("msg", ctx.Interface("a", test.val))
+
+			want, err := json.Marshal(test.want)
+			require.NoError(t, err)
+			assert.Equal(t, `{"lvl":"info","msg":"msg","a":`+string(want)+"}\n", buf.String())
+			assert.NotContains(t, buf.String(), "hunter2")
+		})
+	}
+}
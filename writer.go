@@ -1,8 +1,13 @@
 package logger
 
 import (
+	"encoding/binary"
+	"errors"
 	"io"
+	"math"
 	"sync"
+
+	"github.com/hamba/logger/v2/internal/bytes"
 )
 
 // SyncWriter implements a writer that is synchronised with a lock.
@@ -24,3 +29,45 @@ func (w *SyncWriter) Write(p []byte) (n int, err error) {
 
 	return n, err
 }
+
+var framePool = &sync.Pool{
+	New: func() any {
+		return bytes.NewBuffer(512)
+	},
+}
+
+// FrameWriter implements a writer that prefixes each write with its length
+// as a 4 byte big endian integer, framing the entries of binary formats.
+//
+// Each frame is written to the underlying writer in a single write.
+type FrameWriter struct {
+	w io.Writer
+}
+
+// NewFrameWriter returns a length prefixing writer.
+func NewFrameWriter(w io.Writer) *FrameWriter {
+	return &FrameWriter{w: w}
+}
+
+// Write writes p to the writer as a single frame.
+func (w *FrameWriter) Write(p []byte) (int, error) {
+	if uint64(len(p)) > math.MaxUint32 {
+		return 0, errors.New("logger: frame too large")
+	}
+
+	buf := framePool.Get().(*bytes.Buffer)
+	defer framePool.Put(buf)
+	buf.Reset()
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(p)))
+	buf.Write(size[:])
+	buf.Write(p)
+
+	n, err := w.w.Write(buf.Bytes())
+	n = max(n-len(size), 0)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
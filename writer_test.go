@@ -2,11 +2,15 @@ package logger_test
 
 import (
 	"bytes"
+	"encoding/binary"
+	"io"
 	"testing"
 
 	"github.com/hamba/logger/v2"
+	"github.com/hamba/logger/v2/ctx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 func TestSyncWriter(t *testing.T) {
@@ -20,3 +24,57 @@ func TestSyncWriter(t *testing.T) {
 	assert.Equal(t, 4, n)
 	assert.Equal(t, "test", buf.String())
 }
+
+func TestFrameWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := logger.NewFrameWriter(&buf)
+
+	n, err := w.Write([]byte("test"))
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, "\x00\x00\x00\x04test", buf.String())
+}
+
+func TestFrameWriter_ShortWrite(t *testing.T) {
+	w := logger.NewFrameWriter(&limitWriter{n: 6})
+
+	n, err := w.Write([]byte("test"))
+
+	assert.ErrorIs(t, err, io.ErrShortWrite)
+	assert.Equal(t, 2, n)
+}
+
+func TestFrameWriter_MsgPack(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(logger.NewFrameWriter(&buf), logger.MsgPackFormat(), logger.Info)
+
+	log.Info("first", ctx.Bytes("blob", []byte{0xff, 0x00}))
+	log.Error("second", ctx.Int("n", 300))
+
+	var got []map[string]any
+	for buf.Len() > 0 {
+		require.GreaterOrEqual(t, buf.Len(), 4)
+		size := binary.BigEndian.Uint32(buf.Next(4))
+		require.GreaterOrEqual(t, uint32(buf.Len()), size)
+
+		var entry map[string]any
+		require.NoError(t, msgpack.Unmarshal(buf.Next(int(size)), &entry))
+		got = append(got, entry)
+	}
+
+	want := []map[string]any{
+		{"lvl": "info", "msg": "first", "blob": []byte{0xff, 0x00}},
+		{"lvl": "eror", "msg": "second", "n": uint16(300)},
+	}
+	assert.Equal(t, want, got)
+}
+
+type limitWriter struct {
+	n int
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	return min(len(p), w.n), nil
+}